@@ -0,0 +1,45 @@
+package armory
+
+import "errors"
+
+var ErrAmmunitionNotFound = errors.New("ammunition not found")
+
+type AmmunitionKind string
+
+const (
+	HighExplosiveAmmunitionKind AmmunitionKind = "HE"
+	SmokeAmmunitionKind         AmmunitionKind = "Smoke"
+	IlluminationAmmunitionKind  AmmunitionKind = "Illumination"
+	IncendiaryAmmunitionKind    AmmunitionKind = "Incendiary"
+)
+
+func (k AmmunitionKind) valid() bool {
+	switch k {
+	case HighExplosiveAmmunitionKind, SmokeAmmunitionKind, IlluminationAmmunitionKind, IncendiaryAmmunitionKind:
+		return true
+	default:
+		return false
+	}
+}
+
+// Ammunition describes a round a weapon can fire. Omitted ballistics are inherited from the weapon
+// type. On impact the round leaves an effect with the radius in meters for the lifetime in
+// seconds, no effect is left if the lifetime is zero.
+type Ammunition struct {
+	Id             string         `json:"id" yaml:"id"`
+	Name           string         `json:"name" yaml:"name"`
+	Kind           AmmunitionKind `json:"kind" yaml:"kind"`
+	Velocity       float64        `json:"velocity" yaml:"velocity"`
+	Gravity        float64        `json:"gravity" yaml:"gravity"`
+	MinRange       float64        `json:"minRange" yaml:"minRange"`
+	MaxRange       float64        `json:"maxRange" yaml:"maxRange"`
+	EffectRadius   float64        `json:"effectRadius" yaml:"effectRadius"`
+	EffectLifetime float64        `json:"effectLifetime" yaml:"effectLifetime"`
+}
+
+// defaultAmmunition is given to weapon types which do not list any ammunition.
+var defaultAmmunition = Ammunition{
+	Id:   "HE",
+	Name: "HE",
+	Kind: HighExplosiveAmmunitionKind,
+}
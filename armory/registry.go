@@ -0,0 +1,197 @@
+package armory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrWeaponTypeNotFound = errors.New("weapon type not found")
+var ErrInvalidWeaponType = errors.New("invalid weapon type")
+
+type Registry interface {
+	WeaponTypes() []WeaponType
+	WeaponType(id string) (WeaponType, error)
+}
+
+type registry struct {
+	weaponTypes map[string]WeaponType
+}
+
+func (r *registry) WeaponTypes() []WeaponType {
+	weaponTypes := make([]WeaponType, 0, len(r.weaponTypes))
+	for _, t := range r.weaponTypes {
+		weaponTypes = append(weaponTypes, t)
+	}
+
+	sort.Slice(weaponTypes, func(i, j int) bool {
+		return weaponTypes[i].Name < weaponTypes[j].Name
+	})
+
+	return weaponTypes
+}
+
+func (r *registry) WeaponType(id string) (WeaponType, error) {
+	t, ok := r.weaponTypes[id]
+	if !ok {
+		return WeaponType{}, ErrWeaponTypeNotFound
+	}
+
+	return t, nil
+}
+
+// NewRegistry creates a registry of the weapon types. Omitted elevation limits default to 0 and
+// 90 degrees, an omitted mil system to 6400 mils per circle and omitted ammunition to HE.
+func NewRegistry(weaponTypes []WeaponType) (Registry, error) {
+	r := &registry{
+		make(map[string]WeaponType, len(weaponTypes)),
+	}
+
+	for _, t := range weaponTypes {
+		if t.MaxElevation == 0 {
+			t.MaxElevation = 90
+		}
+
+		if t.MilsPerCircle == 0 {
+			t.MilsPerCircle = 6400
+		}
+
+		t = t.withAmmunition()
+
+		if !t.valid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidWeaponType, t.Id)
+		}
+
+		if _, ok := r.weaponTypes[t.Id]; ok {
+			return nil, fmt.Errorf("%w: duplicate id %q", ErrInvalidWeaponType, t.Id)
+		}
+
+		r.weaponTypes[t.Id] = t
+	}
+
+	return r, nil
+}
+
+// NewDefaultRegistry returns a registry of the built-in weapon types.
+func NewDefaultRegistry() Registry {
+	r, err := NewRegistry(DefaultWeaponTypes)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// LoadRegistry loads a registry from a list of weapon types in a YAML file if the path ends with
+// .yaml or .yml and from a JSON file otherwise. An empty path results in the default registry.
+func LoadRegistry(path string, logger *zap.Logger) (Registry, error) {
+	if path == "" {
+		return NewDefaultRegistry(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var weaponTypes []WeaponType
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &weaponTypes)
+	default:
+		err = json.Unmarshal(data, &weaponTypes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewRegistry(weaponTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("loaded weapon types", zap.String("path", path), zap.Int("count", len(weaponTypes)))
+
+	return r, nil
+}
+
+// DefaultWeaponTypes are the built-in weapon types. Their ids match the former weapon type enum of
+// the API.
+var DefaultWeaponTypes = []WeaponType{
+	{
+		Id:            "StandardMortar",
+		Name:          "Mortar",
+		Velocity:      109.890938,
+		Gravity:       9.8,
+		MinRange:      50,
+		MaxRange:      1230,
+		HighAngle:     true,
+		MilsPerCircle: 6400,
+		Dispersion:    0.5,
+		BlastRadius:   20,
+		Ammunition:    mortarAmmunition,
+	},
+	{
+		Id:            "TechnicalMortar",
+		Name:          "Technical Mortar",
+		Velocity:      109.890938,
+		Gravity:       9.8,
+		MinRange:      50,
+		MaxRange:      1230,
+		HighAngle:     true,
+		MilsPerCircle: 6400,
+		Dispersion:    0.8,
+		BlastRadius:   20,
+		Ammunition:    mortarAmmunition,
+	},
+	{
+		Id:            "Rockets",
+		Name:          "Rockets",
+		Velocity:      300,
+		Gravity:       19.6,
+		MinRange:      100,
+		MaxRange:      4500,
+		HighAngle:     false,
+		MilsPerCircle: 6400,
+		Dispersion:    1,
+		BlastRadius:   25,
+	},
+	{
+		Id:            "HellCanon",
+		Name:          "Hell Cannon",
+		Velocity:      95,
+		Gravity:       9.8,
+		MinRange:      150,
+		MaxRange:      920,
+		HighAngle:     true,
+		MilsPerCircle: 6400,
+		Dispersion:    1.2,
+		BlastRadius:   30,
+	},
+}
+
+var mortarAmmunition = []Ammunition{
+	defaultAmmunition,
+	{
+		Id:             "Smoke",
+		Name:           "Smoke",
+		Kind:           SmokeAmmunitionKind,
+		EffectRadius:   20,
+		EffectLifetime: 40,
+	},
+	{
+		Id:             "Illumination",
+		Name:           "Illumination",
+		Kind:           IlluminationAmmunitionKind,
+		EffectRadius:   150,
+		EffectLifetime: 30,
+	},
+}
@@ -0,0 +1,168 @@
+package armory
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	stdmath "math"
+)
+
+var ErrInvalidTraverseArc = errors.New("traverse arc exceeds the traverse arc of the weapon type")
+
+// WeaponType describes an emplacement or vehicle weapon. Velocities are in meters per second, the
+// gravity in meters per second squared, distances in meters and elevations in degrees. The
+// dispersion is the angle in degrees rounds may leave the barrel off the dialed elevation and
+// azimuth, the blast radius the distance around the impact in which a round is dangerous. The
+// traverse arc in degrees limits the field of fire of emplaced weapons, zero leaves it
+// unrestricted. The faction is empty if the weapon is available to all factions. The first
+// ammunition is loaded by default.
+type WeaponType struct {
+	Id            string  `json:"id" yaml:"id"`
+	Name          string  `json:"name" yaml:"name"`
+	Faction       string  `json:"faction,omitempty" yaml:"faction"`
+	Velocity      float64 `json:"velocity" yaml:"velocity"`
+	Gravity       float64 `json:"gravity" yaml:"gravity"`
+	MinRange      float64 `json:"minRange" yaml:"minRange"`
+	MaxRange      float64 `json:"maxRange" yaml:"maxRange"`
+	MinElevation  float64 `json:"minElevation" yaml:"minElevation"`
+	MaxElevation  float64 `json:"maxElevation" yaml:"maxElevation"`
+	HighAngle     bool    `json:"highAngle" yaml:"highAngle"`
+	MilsPerCircle float64 `json:"milsPerCircle" yaml:"milsPerCircle"`
+	Dispersion    float64 `json:"dispersion" yaml:"dispersion"`
+	BlastRadius   float64 `json:"blastRadius" yaml:"blastRadius"`
+	TraverseArc   float64 `json:"traverseArc" yaml:"traverseArc"`
+
+	Ammunition []Ammunition `json:"ammunition" yaml:"ammunition"`
+}
+
+// DefaultAmmunition returns the ammunition a weapon of the type is loaded with by default.
+func (t WeaponType) DefaultAmmunition() Ammunition {
+	return t.withAmmunition().Ammunition[0]
+}
+
+func (t WeaponType) FindAmmunition(id string) (Ammunition, error) {
+	for _, ammunition := range t.withAmmunition().Ammunition {
+		if ammunition.Id == id {
+			return ammunition, nil
+		}
+	}
+
+	return Ammunition{}, ErrAmmunitionNotFound
+}
+
+// Profile returns the ballistic profile of the default ammunition.
+func (t WeaponType) Profile() ballistics.Profile {
+	return t.AmmunitionProfile(t.DefaultAmmunition())
+}
+
+// AmmunitionProfile returns the ballistic profile of firing the ammunition. The ammunition has to
+// be one of the weapon type.
+func (t WeaponType) AmmunitionProfile(ammunition Ammunition) ballistics.Profile {
+	return ballistics.Profile{
+		Velocity:      ammunition.Velocity,
+		Gravity:       ammunition.Gravity,
+		MinRange:      ammunition.MinRange,
+		MaxRange:      ammunition.MaxRange,
+		MinElevation:  ballistics.RadiansFromDegrees(t.MinElevation),
+		MaxElevation:  ballistics.RadiansFromDegrees(t.MaxElevation),
+		HighAngle:     t.HighAngle,
+		MilsPerCircle: t.MilsPerCircle,
+		Dispersion:    ballistics.RadiansFromDegrees(t.Dispersion),
+		BlastRadius:   t.BlastRadius,
+	}
+}
+
+// DefaultSector returns the field of fire of a newly added weapon of the type facing north.
+func (t WeaponType) DefaultSector() ballistics.Sector {
+	return ballistics.Sector{
+		Arc: ballistics.RadiansFromDegrees(t.TraverseArc),
+	}
+}
+
+// Sector returns the field of fire with the heading and arc in radians. The arc may not be wider
+// than the traverse arc of the type, an arc of zero or a full circle leaves it unrestricted.
+func (t WeaponType) Sector(heading float64, arc float64) (ballistics.Sector, error) {
+	if arc < 0 {
+		return ballistics.Sector{}, errors.New("traverse arc must not be negative")
+	}
+
+	if arc >= 2*stdmath.Pi {
+		arc = 0
+	}
+
+	if limit := t.DefaultSector(); limit.Restricted() && (arc == 0 || arc > limit.Arc) {
+		return ballistics.Sector{}, ErrInvalidTraverseArc
+	}
+
+	return ballistics.Sector{
+		Heading: stdmath.Mod(stdmath.Mod(heading, 2*stdmath.Pi)+2*stdmath.Pi, 2*stdmath.Pi),
+		Arc:     arc,
+	}, nil
+}
+
+// withAmmunition returns the weapon type with at least the default ammunition and all omitted
+// ballistics of its ammunition inherited from the weapon type.
+func (t WeaponType) withAmmunition() WeaponType {
+	ammunition := t.Ammunition
+	if len(ammunition) == 0 {
+		ammunition = []Ammunition{defaultAmmunition}
+	}
+
+	t.Ammunition = make([]Ammunition, len(ammunition))
+	for i, a := range ammunition {
+		if a.Velocity == 0 {
+			a.Velocity = t.Velocity
+		}
+
+		if a.Gravity == 0 {
+			a.Gravity = t.Gravity
+		}
+
+		if a.MinRange == 0 {
+			a.MinRange = t.MinRange
+		}
+
+		if a.MaxRange == 0 {
+			a.MaxRange = t.MaxRange
+		}
+
+		t.Ammunition[i] = a
+	}
+
+	return t
+}
+
+// valid reports whether the weapon type describes a weapon which can be solved for.
+func (t WeaponType) valid() bool {
+	return t.Id != "" &&
+		t.Velocity > 0 &&
+		t.Gravity > 0 &&
+		t.MinRange >= 0 &&
+		t.MaxRange > t.MinRange &&
+		t.MinElevation >= 0 &&
+		t.MaxElevation > t.MinElevation &&
+		t.MaxElevation <= 90 &&
+		t.MilsPerCircle > 0 &&
+		t.Dispersion >= 0 &&
+		t.Dispersion < 45 &&
+		t.BlastRadius >= 0 &&
+		t.TraverseArc >= 0 &&
+		t.TraverseArc <= 360 &&
+		t.validAmmunition()
+}
+
+func (t WeaponType) validAmmunition() bool {
+	ids := make(map[string]struct{}, len(t.Ammunition))
+	for _, a := range t.Ammunition {
+		if _, ok := ids[a.Id]; ok {
+			return false
+		}
+
+		ids[a.Id] = struct{}{}
+
+		if a.Id == "" || !a.Kind.valid() || a.Velocity <= 0 || a.Gravity <= 0 || a.MaxRange <= a.MinRange || a.EffectRadius < 0 || a.EffectLifetime < 0 {
+			return false
+		}
+	}
+
+	return true
+}
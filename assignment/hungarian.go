@@ -0,0 +1,114 @@
+package assignment
+
+import stdmath "math"
+
+// Solve assigns every row of the cost matrix to a distinct column, so that the sum of the costs
+// is minimal. The matrix must not have more rows than columns. Forbidden assignments may be
+// marked by infinite costs, it returns false if no assignment avoiding them exists. The result
+// holds the column assigned to every row.
+func Solve(costs [][]float64) ([]int, bool) {
+	n := len(costs)
+	if n == 0 {
+		return []int{}, true
+	}
+
+	m := len(costs[0])
+	if m < n {
+		return nil, false
+	}
+
+	// Infinite costs are replaced by a cost larger than any finite assignment, so that they are
+	// only chosen if there is no other way.
+	forbidden := 1.0
+	for _, row := range costs {
+		for _, cost := range row {
+			if !stdmath.IsInf(cost, 1) {
+				forbidden += stdmath.Abs(cost)
+			}
+		}
+	}
+
+	cost := func(i int, j int) float64 {
+		if c := costs[i-1][j-1]; !stdmath.IsInf(c, 1) {
+			return c
+		}
+
+		return forbidden
+	}
+
+	// Potentials u and v and the row matched to every column p are 1-indexed, p[0] and column 0
+	// are used to hold the row which is currently being added.
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+
+		minv := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j := range minv {
+			minv[j] = stdmath.Inf(1)
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := stdmath.Inf(1)
+			j1 := 0
+
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+
+				if cur := cost(i0, j) - u[i0] - v[j]; cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	columns := make([]int, n)
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			columns[p[j]-1] = j - 1
+		}
+	}
+
+	for i, j := range columns {
+		if stdmath.IsInf(costs[i][j], 1) {
+			return columns, false
+		}
+	}
+
+	return columns, true
+}
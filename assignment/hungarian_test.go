@@ -0,0 +1,86 @@
+package assignment
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var inf = math.Inf(1)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		costs [][]float64
+		want  []int
+		ok    bool
+	}{
+		{"empty", [][]float64{}, []int{}, true},
+		{"single", [][]float64{{7}}, []int{0}, true},
+		{"known optimum", [][]float64{{4, 1, 3}, {2, 0, 5}, {3, 2, 2}}, []int{1, 0, 2}, true},
+		{"negative costs", [][]float64{{-1, -5}, {-2, -3}}, []int{1, 0}, true},
+		{"rectangular", [][]float64{{5, 1, 4}, {2, 6, 3}}, []int{1, 0}, true},
+		{"more rows than columns", [][]float64{{1}, {2}}, nil, false},
+		{"forbidden avoided", [][]float64{{1, inf}, {2, 3}}, []int{0, 1}, true},
+		{"row infeasible", [][]float64{{1, 2}, {inf, inf}}, nil, false},
+		{"rows compete for one column", [][]float64{{1, inf}, {2, inf}}, nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := Solve(test.costs)
+			if ok != test.ok {
+				t.Fatalf("Solve(%v) ok = %v, want %v", test.costs, ok, test.ok)
+			}
+
+			if test.ok && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Solve(%v) = %v, want %v", test.costs, got, test.want)
+			}
+		})
+	}
+}
+
+// slotCosts expands the durations of every target on every weapon into slots counted from the
+// end of the queue of a weapon, the k-th slot from the end costs k times the duration.
+func slotCosts(durations [][]float64) [][]float64 {
+	n := len(durations)
+	costs := make([][]float64, n)
+	for t, row := range durations {
+		costs[t] = make([]float64, len(row)*n)
+
+		for w, duration := range row {
+			for k := 0; k < n; k++ {
+				costs[t][w*n+k] = float64(k+1) * duration
+			}
+		}
+	}
+
+	return costs
+}
+
+func TestSolveSlots(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations [][]float64
+		want      []int
+	}{
+		// The shorter target is engaged first, so it takes the slot furthest from the end.
+		{"shortest first", [][]float64{{10}, {20}}, []int{1, 0}},
+		{"shortest first reversed", [][]float64{{30}, {10}, {20}}, []int{0, 2, 1}},
+		{"spread across weapons", [][]float64{{10, 12}, {11, 10}}, []int{0, 2}},
+		{"out of range of a weapon", [][]float64{{10, inf}, {30, 15}}, []int{0, 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := Solve(slotCosts(test.durations))
+			if !ok {
+				t.Fatalf("Solve(%v) ok = false, want true", test.durations)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Solve(%v) = %v, want %v", test.durations, got, test.want)
+			}
+		})
+	}
+}
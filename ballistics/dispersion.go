@@ -0,0 +1,70 @@
+package ballistics
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+// Dispersion is the area around the aim point the rounds of a solution may fall into. The range
+// radius lies along the line of fire, the deflection radius across it. Friendly units within the
+// danger close radius may be hit by a round falling at the edge of the dispersion.
+type Dispersion struct {
+	Center            math.Vector3
+	Azimuth           float64
+	RangeRadius       float64
+	DeflectionRadius  float64
+	DangerCloseRadius float64
+}
+
+// Ellipse returns a polygon of the points approximating the dispersion ellipse.
+func (d Dispersion) Ellipse(points int) []math.Vector3 {
+	return math.Ellipse(d.Center, d.Azimuth, d.RangeRadius, d.DeflectionRadius, points)
+}
+
+// DangerClose returns a polygon of the points approximating the danger close circle.
+func (d Dispersion) DangerClose(points int) []math.Vector3 {
+	return math.Circle(d.Center, d.DangerCloseRadius, points)
+}
+
+// dispersion returns the dispersion of rounds leaving the barrel up to the dispersion angle of the
+// profile off the elevation and azimuth of the solution. The solution has to be in range.
+func (p Profile) dispersion(to math.Vector3, solution Solution) Dispersion {
+	rangeRadius := 0.0
+	for _, elevation := range []float64{solution.Elevation - p.Dispersion, solution.Elevation + p.Dispersion} {
+		distance, ok := p.distance(elevation, solution.HeightDelta)
+		if !ok {
+			continue
+		}
+
+		rangeRadius = stdmath.Max(rangeRadius, stdmath.Abs(distance-solution.HorizontalDistance))
+	}
+
+	deflectionRadius := solution.HorizontalDistance * stdmath.Tan(p.Dispersion)
+
+	return Dispersion{
+		Center:            to,
+		Azimuth:           solution.Azimuth,
+		RangeRadius:       rangeRadius,
+		DeflectionRadius:  deflectionRadius,
+		DangerCloseRadius: stdmath.Max(rangeRadius, deflectionRadius) + p.BlastRadius,
+	}
+}
+
+// distance returns the horizontal distance at which a projectile fired with the elevation descends
+// to the height delta. It returns false if the projectile never reaches the height.
+func (p Profile) distance(elevation float64, heightDelta float64) (float64, bool) {
+	if elevation <= 0 || elevation >= stdmath.Pi/2 {
+		return 0, false
+	}
+
+	cos := stdmath.Cos(elevation)
+	tan := stdmath.Tan(elevation)
+	v2 := p.Velocity * p.Velocity * cos * cos
+
+	root := tan*tan - 2*p.Gravity*heightDelta/v2
+	if root < 0 {
+		return 0, false
+	}
+
+	return v2 / p.Gravity * (tan + stdmath.Sqrt(root)), true
+}
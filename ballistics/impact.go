@@ -0,0 +1,60 @@
+package ballistics
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+var ErrInvalidElevation = errors.New("elevation is outside of the elevation limits of the weapon")
+
+// Impact is a point where a projectile hits after travelling the horizontal distance.
+type Impact struct {
+	Position           math.Vector3
+	HorizontalDistance float64
+	TimeOfFlight       float64
+}
+
+// Aim returns the solution of firing with the elevation and azimuth in radians. The horizontal
+// distance of the solution is the one at which the projectile returns to the height it was fired from.
+func (p Profile) Aim(elevation float64, azimuth float64) (Solution, error) {
+	if elevation <= 0 || elevation >= stdmath.Pi/2 || elevation < p.MinElevation || elevation > p.MaxElevation {
+		return Solution{}, ErrInvalidElevation
+	}
+
+	distance := p.Velocity * p.Velocity * stdmath.Sin(2*elevation) / p.Gravity
+
+	solution := Solution{
+		Status:             InRange,
+		Elevation:          elevation,
+		Azimuth:            stdmath.Mod(stdmath.Mod(azimuth, 2*stdmath.Pi)+2*stdmath.Pi, 2*stdmath.Pi),
+		HorizontalDistance: distance,
+		TimeOfFlight:       distance / (p.Velocity * stdmath.Cos(elevation)),
+		Profile:            p,
+	}
+
+	if distance < p.MinRange {
+		solution.Status = TooClose
+	} else if distance > p.MaxRange {
+		solution.Status = TooFar
+	}
+
+	return solution, nil
+}
+
+// ImpactAt returns the impact after travelling the horizontal distance when fired from from.
+func (s Solution) ImpactAt(from math.Vector3, distance float64) Impact {
+	return Impact{
+		Position:           s.PositionAt(from, distance),
+		HorizontalDistance: distance,
+		TimeOfFlight:       distance / (s.Profile.Velocity * stdmath.Cos(s.Elevation)),
+	}
+}
+
+func RadiansFromDegrees(degrees float64) float64 {
+	return degrees * stdmath.Pi / 180
+}
+
+func RadiansFromMils(mils float64, milsPerCircle float64) float64 {
+	return mils * 2 * stdmath.Pi / milsPerCircle
+}
@@ -0,0 +1,105 @@
+package ballistics
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+// Profile describes the exterior ballistics of a projectile. Distances are in meters,
+// velocities in meters per second, the gravity in meters per second squared and the elevation
+// limits of the weapon in radians. The dispersion is the angle in radians rounds may leave the
+// barrel off the dialed elevation and azimuth, the blast radius the distance around the impact
+// in which the round is dangerous.
+type Profile struct {
+	Velocity      float64
+	Gravity       float64
+	MinRange      float64
+	MaxRange      float64
+	MinElevation  float64
+	MaxElevation  float64
+	HighAngle     bool
+	MilsPerCircle float64
+	Dispersion    float64
+	BlastRadius   float64
+}
+
+// Solve computes the firing solution to hit to when firing from from.
+//
+// The map plane is spanned by X (east) and Y (south), Z is the height. The azimuth
+// is measured clockwise from north.
+func (p Profile) Solve(from math.Vector3, to math.Vector3) Solution {
+	delta := to.Sub(from)
+	distance := float64(delta.HorizontalLength())
+	heightDelta := float64(delta.Z)
+
+	solution := Solution{
+		Status:             InRange,
+		Azimuth:            azimuth(delta),
+		HorizontalDistance: distance,
+		HeightDelta:        heightDelta,
+		Profile:            p,
+	}
+
+	if distance == 0 || distance < p.MinRange {
+		solution.Status = TooClose
+		return solution
+	}
+
+	if distance > p.MaxRange {
+		solution.Status = TooFar
+		return solution
+	}
+
+	elevation, ok := p.elevation(distance, heightDelta)
+	if !ok {
+		solution.Status = TooFar
+		return solution
+	}
+
+	// A high angle weapon has to elevate further to shoot closer, a low angle weapon to shoot further.
+	if elevation > p.MaxElevation {
+		solution.Status = TooClose
+		if !p.HighAngle {
+			solution.Status = TooFar
+		}
+		return solution
+	}
+
+	if elevation < p.MinElevation {
+		solution.Status = TooFar
+		if !p.HighAngle {
+			solution.Status = TooClose
+		}
+		return solution
+	}
+
+	solution.Elevation = elevation
+	solution.TimeOfFlight = distance / (p.Velocity * stdmath.Cos(elevation))
+	solution.Dispersion = p.dispersion(to, solution)
+
+	return solution
+}
+
+func (p Profile) elevation(distance float64, heightDelta float64) (float64, bool) {
+	v2 := p.Velocity * p.Velocity
+	root := v2*v2 - p.Gravity*(p.Gravity*distance*distance+2*heightDelta*v2)
+	if root < 0 {
+		return 0, false
+	}
+
+	sqrt := stdmath.Sqrt(root)
+	if !p.HighAngle {
+		sqrt = -sqrt
+	}
+
+	return stdmath.Atan((v2 + sqrt) / (p.Gravity * distance)), true
+}
+
+func azimuth(delta math.Vector3) float64 {
+	a := stdmath.Atan2(float64(delta.X), -float64(delta.Y))
+	if a < 0 {
+		a += 2 * stdmath.Pi
+	}
+
+	return a
+}
@@ -0,0 +1,111 @@
+package ballistics
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+	"testing"
+)
+
+// standardMortar is the profile of the built-in StandardMortar weapon type.
+var standardMortar = Profile{
+	Velocity:      109.890938,
+	Gravity:       9.8,
+	MinRange:      50,
+	MaxRange:      1230,
+	MinElevation:  0,
+	MaxElevation:  stdmath.Pi / 2,
+	HighAngle:     true,
+	MilsPerCircle: 6400,
+}
+
+// lowAngle is a profile firing below 45 degrees with the same projectile as the mortar.
+var lowAngle = Profile{
+	Velocity:      109.890938,
+	Gravity:       9.8,
+	MinRange:      50,
+	MaxRange:      1230,
+	MinElevation:  0,
+	MaxElevation:  stdmath.Pi / 2,
+	HighAngle:     false,
+	MilsPerCircle: 6400,
+}
+
+func withElevationLimits(p Profile, minDegrees float64, maxDegrees float64) Profile {
+	p.MinElevation = RadiansFromDegrees(minDegrees)
+	p.MaxElevation = RadiansFromDegrees(maxDegrees)
+	return p
+}
+
+func TestProfileSolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      Profile
+		to           math.Vector3
+		status       Status
+		mils         float64
+		timeOfFlight float64
+	}{
+		{"max range", standardMortar, math.Vector3{Y: -1230}, InRange, 830.76, 16.33},
+		{"min range", standardMortar, math.Vector3{Y: -50}, InRange, 1579.33, 22.42},
+		{"mid range", standardMortar, math.Vector3{Y: -1000}, InRange, 1117.82, 19.96},
+		{"target above", standardMortar, math.Vector3{Y: -1000, Z: 100}, InRange, 1072.14, 18.37},
+		{"target below", standardMortar, math.Vector3{Y: -1000, Z: -100}, InRange, 1148.60, 21.22},
+		{"same position", standardMortar, math.Vector3{}, TooClose, 0, 0},
+		{"below min range", standardMortar, math.Vector3{Y: -49}, TooClose, 0, 0},
+		{"beyond max range", standardMortar, math.Vector3{Y: -1231}, TooFar, 0, 0},
+		{"target above out of reach", standardMortar, math.Vector3{Y: -1200, Z: 100}, TooFar, 0, 0},
+		{"low angle", lowAngle, math.Vector3{Y: -1000}, InRange, 482.18, 10.22},
+		{"low angle max range", lowAngle, math.Vector3{Y: -1230}, InRange, 769.24, 15.37},
+		{"high angle above max elevation", withElevationLimits(standardMortar, 0, 85), math.Vector3{Y: -50}, TooClose, 0, 0},
+		{"high angle below min elevation", withElevationLimits(standardMortar, 50, 90), math.Vector3{Y: -1230}, TooFar, 0, 0},
+		{"low angle above max elevation", withElevationLimits(lowAngle, 0, 40), math.Vector3{Y: -1230}, TooFar, 0, 0},
+		{"low angle below min elevation", withElevationLimits(lowAngle, 30, 90), math.Vector3{Y: -1000}, TooClose, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			solution := test.profile.Solve(math.Vector3{}, test.to)
+			if solution.Status != test.status {
+				t.Fatalf("Solve(%v) status = %v, want %v", test.to, solution.Status, test.status)
+			}
+
+			if test.status != InRange {
+				return
+			}
+
+			if mils := Mils(solution.Elevation, test.profile.MilsPerCircle); stdmath.Abs(mils-test.mils) > 0.01 {
+				t.Errorf("Solve(%v) elevation = %.2f mils, want %.2f", test.to, mils, test.mils)
+			}
+
+			if stdmath.Abs(solution.TimeOfFlight-test.timeOfFlight) > 0.01 {
+				t.Errorf("Solve(%v) time of flight = %.2f s, want %.2f", test.to, solution.TimeOfFlight, test.timeOfFlight)
+			}
+		})
+	}
+}
+
+func TestProfileSolveAzimuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      math.Vector3
+		degrees float64
+	}{
+		{"north", math.Vector3{Y: -500}, 0},
+		{"north east", math.Vector3{X: 500, Y: -500}, 45},
+		{"east", math.Vector3{X: 500}, 90},
+		{"south east", math.Vector3{X: 500, Y: 500}, 135},
+		{"south", math.Vector3{Y: 500}, 180},
+		{"south west", math.Vector3{X: -500, Y: 500}, 225},
+		{"west", math.Vector3{X: -500}, 270},
+		{"north west", math.Vector3{X: -500, Y: -500}, 315},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			solution := standardMortar.Solve(math.Vector3{}, test.to)
+			if degrees := Degrees(solution.Azimuth); stdmath.Abs(degrees-test.degrees) > 0.001 {
+				t.Errorf("Solve(%v) azimuth = %.3f degrees, want %.3f", test.to, degrees, test.degrees)
+			}
+		})
+	}
+}
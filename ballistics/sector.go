@@ -0,0 +1,53 @@
+package ballistics
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+// Sector is the field of fire of a weapon. The weapon can traverse across the arc in radians
+// centered on the heading, which is measured clockwise from north. An arc of zero leaves the
+// weapon unrestricted.
+type Sector struct {
+	Heading float64
+	Arc     float64
+}
+
+func (s Sector) Restricted() bool {
+	return s.Arc > 0 && s.Arc < 2*stdmath.Pi
+}
+
+// Contains reports whether the azimuth in radians lies within the arc.
+func (s Sector) Contains(azimuth float64) bool {
+	if !s.Restricted() {
+		return true
+	}
+
+	// The offset of the azimuth from the heading normalized to [-pi, pi).
+	offset := stdmath.Mod(azimuth-s.Heading+stdmath.Pi, 2*stdmath.Pi)
+	if offset < 0 {
+		offset += 2 * stdmath.Pi
+	}
+
+	return stdmath.Abs(offset-stdmath.Pi) <= s.Arc/2
+}
+
+// Restrict marks a solution in range as out of the arc if its azimuth lies outside of the sector.
+func (s Sector) Restrict(solution Solution) Solution {
+	if solution.Status == InRange && !s.Contains(solution.Azimuth) {
+		solution.Status = OutOfArc
+	}
+
+	return solution
+}
+
+// Polygon returns a polygon of the points approximating the area between the minimum and maximum
+// range of the profile within the sector around the position. An unrestricted sector results in a
+// circle of the maximum range.
+func (s Sector) Polygon(position math.Vector3, profile Profile, points int) []math.Vector3 {
+	if !s.Restricted() {
+		return math.Circle(position, profile.MaxRange, points)
+	}
+
+	return math.Sector(position, s.Heading, s.Arc, profile.MinRange, profile.MaxRange, points)
+}
@@ -0,0 +1,54 @@
+package ballistics
+
+import stdmath "math"
+
+type Status int32
+
+const (
+	InRange Status = iota
+	TooClose
+	TooFar
+	// OutOfArc marks a solution in range whose azimuth lies outside of the sector of the weapon.
+	OutOfArc
+)
+
+// Solution is the result of Profile.Solve. Angles are stored in radians, Elevation,
+// TimeOfFlight and Dispersion are only set if the Status is InRange or OutOfArc.
+type Solution struct {
+	Status             Status
+	Elevation          float64
+	Azimuth            float64
+	HorizontalDistance float64
+	HeightDelta        float64
+	TimeOfFlight       float64
+	Dispersion         Dispersion
+	Profile            Profile
+}
+
+func (s Solution) InRange() bool {
+	return s.Status == InRange
+}
+
+func (s Solution) ElevationDegrees() float64 {
+	return Degrees(s.Elevation)
+}
+
+func (s Solution) ElevationMils() float64 {
+	return Mils(s.Elevation, s.Profile.MilsPerCircle)
+}
+
+func (s Solution) AzimuthDegrees() float64 {
+	return Degrees(s.Azimuth)
+}
+
+func (s Solution) AzimuthMils() float64 {
+	return Mils(s.Azimuth, s.Profile.MilsPerCircle)
+}
+
+func Degrees(radians float64) float64 {
+	return radians * 180 / stdmath.Pi
+}
+
+func Mils(radians float64, milsPerCircle float64) float64 {
+	return radians * milsPerCircle / (2 * stdmath.Pi)
+}
@@ -0,0 +1,21 @@
+package ballistics
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+// PositionAt returns the position of the projectile after travelling the horizontal distance when
+// fired from from. The result is only meaningful if the solution is in range.
+func (s Solution) PositionAt(from math.Vector3, distance float64) math.Vector3 {
+	cos := stdmath.Cos(s.Elevation)
+	velocity := s.Profile.Velocity * cos
+
+	height := distance*stdmath.Tan(s.Elevation) - s.Profile.Gravity*distance*distance/(2*velocity*velocity)
+
+	return math.Vector3{
+		X: from.X + float32(distance*stdmath.Sin(s.Azimuth)),
+		Y: from.Y - float32(distance*stdmath.Cos(s.Azimuth)),
+		Z: from.Z + float32(height),
+	}
+}
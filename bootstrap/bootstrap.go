@@ -1,6 +1,7 @@
 package bootstrap
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"fmt"
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -10,15 +11,21 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/gorilla/websocket"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/auth"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/crypto"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql/generated"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/log"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/session/storage"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
 	"github.com/rs/cors"
 	"go.uber.org/zap"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -35,6 +42,14 @@ type bootstrapper struct {
 	logger              *zap.Logger
 	enablePlayground    bool
 	enableIntrospection bool
+	janitorInterval     time.Duration
+	sessionIdleTtl      time.Duration
+	sessionEmptyGrace   time.Duration
+	storageBackend      string
+	storageFilepath     string
+	snapshotInterval    time.Duration
+	heightmapDir        string
+	weaponTypesFilepath string
 }
 
 func New(
@@ -44,7 +59,15 @@ func New(
 	wsKeepAlive time.Duration,
 	allowedOrigins []string,
 	enablePlayground bool,
-	enableIntrospection bool) (Bootstrapper, error) {
+	enableIntrospection bool,
+	janitorInterval time.Duration,
+	sessionIdleTtl time.Duration,
+	sessionEmptyGrace time.Duration,
+	storageBackend string,
+	storageFilepath string,
+	snapshotInterval time.Duration,
+	heightmapDir string,
+	weaponTypesFilepath string) (Bootstrapper, error) {
 	logger, _ := zap.NewProduction()
 
 	privateKey, err := crypto.ParseEcdsaPemPrivateKey(privateKeyFilepath)
@@ -59,7 +82,15 @@ func New(
 		zap.Duration("wsKeepAlive", wsKeepAlive),
 		zap.Strings("allowedOrigins", allowedOrigins),
 		zap.Bool("enablePlayground", enablePlayground),
-		zap.Bool("enableIntrospection", enableIntrospection))
+		zap.Bool("enableIntrospection", enableIntrospection),
+		zap.Duration("janitorInterval", janitorInterval),
+		zap.Duration("sessionIdleTtl", sessionIdleTtl),
+		zap.Duration("sessionEmptyGrace", sessionEmptyGrace),
+		zap.String("storageBackend", storageBackend),
+		zap.String("storageFilepath", storageFilepath),
+		zap.Duration("snapshotInterval", snapshotInterval),
+		zap.String("heightmapDir", heightmapDir),
+		zap.String("weaponTypesFilepath", weaponTypesFilepath))
 
 	return &bootstrapper{
 		host:                host,
@@ -70,15 +101,55 @@ func New(
 		logger:              logger,
 		enablePlayground:    enablePlayground,
 		enableIntrospection: enableIntrospection,
+		janitorInterval:     janitorInterval,
+		sessionIdleTtl:      sessionIdleTtl,
+		sessionEmptyGrace:   sessionEmptyGrace,
+		storageBackend:      storageBackend,
+		storageFilepath:     storageFilepath,
+		snapshotInterval:    snapshotInterval,
+		heightmapDir:        heightmapDir,
+		weaponTypesFilepath: weaponTypesFilepath,
 	}, nil
 }
 
 func (b *bootstrapper) Listen() error {
 	b.logger.Info("setting up listener")
+	mapCatalog := gamemap.NewDefaultCatalog()
+
+	heightmaps, err := terrain.LoadStore(b.heightmapDir, mapCatalog, b.logger)
+	if err != nil {
+		return err
+	}
+
+	weaponRegistry, err := armory.LoadRegistry(b.weaponTypesFilepath, b.logger)
+	if err != nil {
+		return err
+	}
+
+	sessionStorage, err := b.newStorage(heightmaps, weaponRegistry)
+	if err != nil {
+		return err
+	}
+
+	if persistentStorage, ok := sessionStorage.(storage.PersistentStorage); ok {
+		defer func() {
+			if err := persistentStorage.Close(); err != nil {
+				b.logger.Error("could not close session storage", zap.Error(err))
+			}
+		}()
+	}
+
+	janitor := storage.NewJanitor(sessionStorage, b.janitorInterval, b.sessionIdleTtl, b.sessionEmptyGrace, b.logger)
+	janitor.Start()
+	defer janitor.Stop()
+
 	config := generated.Config{
 		Resolvers: &graphql.Resolver{
 			EcdsaKey:       b.privateKey,
-			SessionStorage: storage.NewStorage(),
+			SessionStorage: sessionStorage,
+			MapCatalog:     mapCatalog,
+			Heightmaps:     heightmaps,
+			WeaponRegistry: weaponRegistry,
 		},
 	}
 
@@ -129,9 +200,44 @@ func (b *bootstrapper) Listen() error {
 		r.Handle("/graphql/playground", playground.Handler("Squadmortar Session Server", fmt.Sprintf("http://%s/graphql", b.addr())))
 	}
 
+	server := &http.Server{
+		Addr:    b.addr(),
+		Handler: r,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
 	b.logger.Info("now listening", zap.String("host", b.host), zap.Uint16("port", b.port))
 	defer b.logger.Info("stopped http server", zap.String("host", b.host), zap.Uint16("port", b.port))
-	return http.ListenAndServe(b.addr(), r)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		b.logger.Info("shutting down http server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+func (b *bootstrapper) newStorage(heightmaps terrain.Store, weaponTypes armory.Registry) (storage.Storage, error) {
+	switch b.storageBackend {
+	case "memory":
+		return storage.NewStorage(), nil
+	case "bolt":
+		return storage.NewBoltStorage(b.storageFilepath, b.snapshotInterval, heightmaps, weaponTypes, b.logger)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", b.storageBackend)
+	}
 }
 
 func (b *bootstrapper) addr() string {
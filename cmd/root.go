@@ -24,7 +24,15 @@ var rootCmd = &cobra.Command{
 			websocketKeepAlive,
 			allowedOrigins,
 			enablePlayground,
-			enableIntrospection)
+			enableIntrospection,
+			janitorInterval,
+			sessionIdleTtl,
+			sessionEmptyGrace,
+			storageBackend,
+			storageFilepath,
+			snapshotInterval,
+			heightmapDir,
+			weaponTypesFilepath)
 		if err != nil {
 			panic(err)
 		}
@@ -51,6 +59,14 @@ var websocketKeepAlive time.Duration
 var allowedOrigins []string
 var enablePlayground bool
 var enableIntrospection bool
+var janitorInterval time.Duration
+var sessionIdleTtl time.Duration
+var sessionEmptyGrace time.Duration
+var storageBackend string
+var storageFilepath string
+var snapshotInterval time.Duration
+var heightmapDir string
+var weaponTypesFilepath string
 
 func init() {
 	rootCmd.Flags().StringVar(&host, "host", "localhost", "Listener host for the GraphQL server.")
@@ -60,4 +76,12 @@ func init() {
 	rootCmd.Flags().StringSliceVar(&allowedOrigins, "allowed-origins", []string{"*"}, "Allowed origin for CORS")
 	rootCmd.Flags().BoolVar(&enablePlayground, "enable-playground", false, "Enables the GraphiQL playground under /graphql/playground. Only available for HTTP")
 	rootCmd.Flags().BoolVar(&enableIntrospection, "enable-introspection", false, "Enables introspection for GraphQL responses. Disable it in production.")
+	rootCmd.Flags().DurationVar(&janitorInterval, "janitor-interval", time.Minute, "Interval in which idle and empty sessions are removed.")
+	rootCmd.Flags().DurationVar(&sessionIdleTtl, "session-idle-ttl", time.Hour*24, "Duration without any activity or subscribers after which a session is removed. 0 disables it.")
+	rootCmd.Flags().DurationVar(&sessionEmptyGrace, "session-empty-grace-period", time.Minute*30, "Duration a session may stay without any users before it is removed. 0 disables it.")
+	rootCmd.Flags().StringVar(&storageBackend, "storage", "memory", "Session storage backend. Either memory or bolt.")
+	rootCmd.Flags().StringVar(&storageFilepath, "storage-file", "./sessions.db", "Database file of the bolt storage backend.")
+	rootCmd.Flags().DurationVar(&snapshotInterval, "storage-snapshot-interval", time.Second*30, "Interval in which the bolt storage backend snapshots all sessions. 0 only snapshots on shutdown.")
+	rootCmd.Flags().StringVar(&heightmapDir, "heightmap-dir", "", "Directory containing heightmaps named <map id>.png or <map id>.raw with an optional <map id>.json for the height scale and offset. Raw values are interpreted as centimeters by default.")
+	rootCmd.Flags().StringVar(&weaponTypesFilepath, "weapon-types-file", "", "JSON or YAML file listing the available weapon types. The built-in weapon types are used if none is given.")
 }
@@ -2,9 +2,12 @@ package eventhandler
 
 type Delegate[S any, E any] func(sender S, event E)
 
+// Token identifies a delegate added to an event, as functions are not comparable.
+type Token uint64
+
 type Event[S any, E any] interface {
-	Add(delegate Delegate[S, E])
-	Remove(delegate Delegate[S, E])
+	Add(delegate Delegate[S, E]) Token
+	Remove(token Token)
 }
 
 type EventHandler[S any, E any] interface {
@@ -12,48 +15,58 @@ type EventHandler[S any, E any] interface {
 	Invoke(sender S, event E)
 }
 
+type registration[S any, E any] struct {
+	token    Token
+	delegate Delegate[S, E]
+}
+
 type eventHandler[S any, E any] struct {
-	delegates []Delegate[S, E]
+	registrations []registration[S, E]
+	nextToken     Token
 }
 
-func (e *eventHandler[S, E]) Add(delegate Delegate[S, E]) {
-	e.delegates = append(e.delegates, delegate)
+func (e *eventHandler[S, E]) Add(delegate Delegate[S, E]) Token {
+	e.nextToken++
+
+	e.registrations = append(e.registrations, registration[S, E]{
+		token:    e.nextToken,
+		delegate: delegate,
+	})
+
+	return e.nextToken
 }
 
-func (e *eventHandler[S, E]) Remove(delegate Delegate[S, E]) {
-	idx := e.findDelegateIndex(delegate)
+func (e *eventHandler[S, E]) Remove(token Token) {
+	idx := e.findRegistrationIndex(token)
 	if idx == -1 {
 		return
 	}
 
-	e.delegates[idx] = e.delegates[len(e.delegates)-1]
-	e.delegates = e.delegates[:len(e.delegates)-1]
+	e.registrations[idx] = e.registrations[len(e.registrations)-1]
+	e.registrations = e.registrations[:len(e.registrations)-1]
 }
 
-func (e *eventHandler[S, E]) findDelegateIndex(delegate Delegate[S, E]) int {
-	idx := 0
-
-	for _, d := range e.delegates {
-		if &d == &delegate {
+func (e *eventHandler[S, E]) findRegistrationIndex(token Token) int {
+	for idx, r := range e.registrations {
+		if r.token == token {
 			return idx
 		}
-
-		idx++
 	}
 
-	return idx
+	return -1
 }
 
 func (e *eventHandler[S, E]) Invoke(sender S, event E) {
-	tmpDelegates := e.delegates
+	tmpRegistrations := e.registrations
 
-	for _, d := range tmpDelegates {
-		d(sender, event)
+	for _, r := range tmpRegistrations {
+		r.delegate(sender, event)
 	}
 }
 
 func New[S any, E any]() EventHandler[S, E] {
 	return &eventHandler[S, E]{
-		make([]Delegate[S, E], 0),
+		make([]registration[S, E], 0),
+		0,
 	}
 }
@@ -0,0 +1,71 @@
+package eventhandler
+
+import (
+	"reflect"
+	"testing"
+)
+
+type counter struct {
+	calls int
+}
+
+func (c *counter) handle(_ string, _ int) {
+	c.calls++
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove []int
+		want   []int
+	}{
+		{"none", []int{}, []int{1, 1, 1}},
+		{"first", []int{0}, []int{0, 1, 1}},
+		{"middle", []int{1}, []int{1, 0, 1}},
+		{"last", []int{2}, []int{1, 1, 0}},
+		{"twice", []int{1, 1}, []int{1, 0, 1}},
+		{"all", []int{0, 1, 2}, []int{0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := New[string, int]()
+
+			// Method values of the same method share their code, so only the tokens tell them apart.
+			counters := []*counter{{}, {}, {}}
+			tokens := make([]Token, len(counters))
+			for i, c := range counters {
+				tokens[i] = handler.Add(c.handle)
+			}
+
+			for _, i := range test.remove {
+				handler.Remove(tokens[i])
+			}
+
+			handler.Invoke("sender", 0)
+
+			got := make([]int, len(counters))
+			for i, c := range counters {
+				got[i] = c.calls
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("calls = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveUnknownToken(t *testing.T) {
+	handler := New[string, int]()
+
+	c := &counter{}
+	handler.Add(c.handle)
+	handler.Remove(Token(42))
+
+	handler.Invoke("sender", 0)
+
+	if c.calls != 1 {
+		t.Errorf("calls = %d, want 1", c.calls)
+	}
+}
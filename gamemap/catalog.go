@@ -0,0 +1,96 @@
+package gamemap
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"sort"
+)
+
+var ErrMapNotFound = errors.New("map not found")
+
+type Catalog interface {
+	Maps() []Map
+	Map(id string) (Map, error)
+}
+
+type catalog struct {
+	maps map[string]Map
+}
+
+func (c *catalog) Maps() []Map {
+	maps := make([]Map, 0, len(c.maps))
+	for _, m := range c.maps {
+		maps = append(maps, m)
+	}
+
+	sort.Slice(maps, func(i, j int) bool {
+		return maps[i].Name < maps[j].Name
+	})
+
+	return maps
+}
+
+func (c *catalog) Map(id string) (Map, error) {
+	m, ok := c.maps[id]
+	if !ok {
+		return Map{}, ErrMapNotFound
+	}
+
+	return m, nil
+}
+
+func NewCatalog(maps []Map) Catalog {
+	c := &catalog{
+		make(map[string]Map, len(maps)),
+	}
+
+	for _, m := range maps {
+		c.maps[m.Id] = m
+	}
+
+	return c
+}
+
+// NewDefaultCatalog returns a catalog of the built-in Squad maps.
+func NewDefaultCatalog() Catalog {
+	return NewCatalog(defaultMaps)
+}
+
+var defaultMaps = []Map{
+	newSquareMap("albasrah", "Al Basrah", 3040),
+	newSquareMap("anvil", "Anvil", 4064),
+	newSquareMap("belaya", "Belaya Pass", 3904),
+	newSquareMap("blackcoast", "Black Coast", 4032),
+	newSquareMap("chora", "Chora", 4064),
+	newSquareMap("fallujah", "Fallujah", 3048),
+	newSquareMap("foolsroad", "Fool's Road", 1744),
+	newSquareMap("goosebay", "Goose Bay", 4000),
+	newSquareMap("gorodok", "Gorodok", 4340),
+	newSquareMap("harju", "Harju", 4064),
+	newSquareMap("jensensrange", "Jensen's Range", 2032),
+	newSquareMap("kamdesh", "Kamdesh Highlands", 4064),
+	newSquareMap("kohat", "Kohat Toi", 4617),
+	newSquareMap("kokan", "Kokan", 2496),
+	newSquareMap("lashkar", "Lashkar Valley", 4800),
+	newSquareMap("logar", "Logar Valley", 1761),
+	newSquareMap("manicouagan", "Manicouagan", 4064),
+	newSquareMap("mestia", "Mestia", 2400),
+	newSquareMap("mutaha", "Mutaha", 2856),
+	newSquareMap("narva", "Narva", 3048),
+	newSquareMap("sanxian", "Sanxian Islands", 4064),
+	newSquareMap("skorpo", "Skorpo", 7600),
+	newSquareMap("sumari", "Sumari Bala", 1300),
+	newSquareMap("tallil", "Tallil Outskirts", 4600),
+	newSquareMap("yehorivka", "Yehorivka", 5000),
+}
+
+func newSquareMap(id string, name string, size float32) Map {
+	return Map{
+		Id:       id,
+		Name:     name,
+		Width:    size,
+		Height:   size,
+		GridSize: 300,
+		Origin:   math.Vector3{},
+	}
+}
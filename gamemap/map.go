@@ -0,0 +1,30 @@
+package gamemap
+
+import "github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+
+// Map describes the playable area of a Squad map. Sizes are in meters, the origin is the
+// north-west corner of the map in world coordinates.
+type Map struct {
+	Id       string       `json:"id"`
+	Name     string       `json:"name"`
+	Width    float32      `json:"width"`
+	Height   float32      `json:"height"`
+	GridSize float32      `json:"gridSize"`
+	Origin   math.Vector3 `json:"origin"`
+}
+
+// Contains reports whether the position lies within the map bounds. The height is ignored.
+func (m Map) Contains(position math.Vector3) bool {
+	x := position.X - m.Origin.X
+	y := position.Y - m.Origin.Y
+
+	return x >= 0 && x <= m.Width && y >= 0 && y <= m.Height
+}
+
+// Grid returns the keypad grid of the map.
+func (m Map) Grid() math.Grid {
+	return math.Grid{
+		Origin: m.Origin,
+		Size:   m.GridSize,
+	}
+}
@@ -0,0 +1,25 @@
+package graphql
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+)
+
+// profile returns the ballistic profile of the ammunition of the weapon type, or of its first
+// ammunition if none is given.
+func (r *Resolver) profile(weaponTypeId string, ammunitionId *string) (ballistics.Profile, error) {
+	t, err := r.WeaponRegistry.WeaponType(weaponTypeId)
+	if err != nil {
+		return ballistics.Profile{}, err
+	}
+
+	if ammunitionId == nil {
+		return t.Profile(), nil
+	}
+
+	ammunition, err := t.FindAmmunition(*ammunitionId)
+	if err != nil {
+		return ballistics.Profile{}, err
+	}
+
+	return t.AmmunitionProfile(ammunition), nil
+}
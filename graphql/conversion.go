@@ -1,10 +1,17 @@
 package graphql
 
 import (
+	"github.com/google/uuid"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql/model"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/pattern"
 	session2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
+	"time"
 )
 
 func UserToGraphQL(user session2.User) *model.User {
@@ -12,9 +19,229 @@ func UserToGraphQL(user session2.User) *model.User {
 		return nil
 	}
 
+	var position *math.Vector3
+	if p, ok := user.Position(); ok {
+		position = &p
+	}
+
 	return &model.User{
-		ClientGUID: user.ClientUuid().String(),
-		Name:       user.Name(),
+		ClientGUID:        user.ClientUuid().String(),
+		Name:              user.Name(),
+		Role:              RoleToGraphQL(user.Role()),
+		Position:          position,
+		PositionExpiresAt: TimeToGraphQL(user.PositionExpiresAt()),
+	}
+}
+
+func RoleToGraphQL(role session2.Role) model.Role {
+	switch role {
+	case session2.SpotterRole:
+		return model.RoleSpotter
+	case session2.GunnerRole:
+		return model.RoleGunner
+	case session2.SpectatorRole:
+		return model.RoleSpectator
+	case session2.CommanderRole:
+		return model.RoleCommander
+	default:
+		return model.RoleSpectator
+	}
+}
+
+func RoleFromGraphQL(role model.Role) session2.Role {
+	switch role {
+	case model.RoleSpotter:
+		return session2.SpotterRole
+	case model.RoleGunner:
+		return session2.GunnerRole
+	case model.RoleSpectator:
+		return session2.SpectatorRole
+	case model.RoleCommander:
+		return session2.CommanderRole
+	default:
+		return session2.SpectatorRole
+	}
+}
+
+func FireMissionStateToGraphQL(state session2.FireMissionState) model.FireMissionState {
+	switch state {
+	case session2.AcknowledgedFireMissionState:
+		return model.FireMissionStateAcknowledged
+	case session2.FiringFireMissionState:
+		return model.FireMissionStateFiring
+	case session2.SplashFireMissionState:
+		return model.FireMissionStateSplash
+	case session2.CompleteFireMissionState:
+		return model.FireMissionStateComplete
+	case session2.CancelledFireMissionState:
+		return model.FireMissionStateCancelled
+	case session2.RequestedFireMissionState:
+		return model.FireMissionStateRequested
+	default:
+		return model.FireMissionStateRequested
+	}
+}
+
+func FireMissionStateFromGraphQL(state model.FireMissionState) session2.FireMissionState {
+	switch state {
+	case model.FireMissionStateAcknowledged:
+		return session2.AcknowledgedFireMissionState
+	case model.FireMissionStateFiring:
+		return session2.FiringFireMissionState
+	case model.FireMissionStateSplash:
+		return session2.SplashFireMissionState
+	case model.FireMissionStateComplete:
+		return session2.CompleteFireMissionState
+	case model.FireMissionStateCancelled:
+		return session2.CancelledFireMissionState
+	case model.FireMissionStateRequested:
+		return session2.RequestedFireMissionState
+	default:
+		return session2.RequestedFireMissionState
+	}
+}
+
+func FireMissionToGraphQL(fireMission session2.FireMission) *model.FireMission {
+	if fireMission == nil {
+		return nil
+	}
+
+	return &model.FireMission{
+		ID:             int(fireMission.Id()),
+		State:          FireMissionStateToGraphQL(fireMission.State()),
+		Rounds:         fireMission.Rounds(),
+		Targets:        slice.Map(fireMission.Targets(), TargetToGraphQL),
+		Weapons:        slice.Map(fireMission.Weapons(), WeaponToGraphQL),
+		RequestedBy:    UserToGraphQL(fireMission.RequestedBy()),
+		RequestedAt:    fireMission.RequestedAt(),
+		AcknowledgedBy: UserToGraphQL(fireMission.AcknowledgedBy()),
+	}
+}
+
+func TimeOnTargetStateToGraphQL(state session2.TimeOnTargetState) model.TimeOnTargetState {
+	switch state {
+	case session2.CompleteTimeOnTargetState:
+		return model.TimeOnTargetStateComplete
+	case session2.CancelledTimeOnTargetState:
+		return model.TimeOnTargetStateCancelled
+	case session2.ScheduledTimeOnTargetState:
+		return model.TimeOnTargetStateScheduled
+	default:
+		return model.TimeOnTargetStateScheduled
+	}
+}
+
+func TimeOnTargetShotToGraphQL(shot session2.TimeOnTargetShot) *model.TimeOnTargetShot {
+	return &model.TimeOnTargetShot{
+		Weapon:   WeaponToGraphQL(shot.Weapon),
+		Solution: FiringSolutionToGraphQL(shot.Solution, nil),
+		FireAt:   shot.FireAt,
+	}
+}
+
+func TimeOnTargetToGraphQL(timeOnTarget session2.TimeOnTarget) *model.TimeOnTarget {
+	if timeOnTarget == nil {
+		return nil
+	}
+
+	return &model.TimeOnTarget{
+		ID:          int(timeOnTarget.Id()),
+		State:       TimeOnTargetStateToGraphQL(timeOnTarget.State()),
+		Target:      TargetToGraphQL(timeOnTarget.Target()),
+		Shots:       slice.Map(timeOnTarget.Shots(), TimeOnTargetShotToGraphQL),
+		ImpactAt:    timeOnTarget.ImpactAt(),
+		RequestedBy: UserToGraphQL(timeOnTarget.RequestedBy()),
+	}
+}
+
+// TimeOnTargetCountdownToGraphQL converts a countdown tick. Weapons fire at the tick if it is their fire time.
+func TimeOnTargetCountdownToGraphQL(countdown *session2.TimeOnTargetCountdown) *model.TimeOnTargetCountdown {
+	if countdown == nil {
+		return nil
+	}
+
+	firingWeaponIds := make([]int, 0)
+	for _, shot := range countdown.TimeOnTarget.Shots() {
+		if shot.FireAt.Equal(countdown.At) {
+			firingWeaponIds = append(firingWeaponIds, int(shot.Weapon.Id()))
+		}
+	}
+
+	return &model.TimeOnTargetCountdown{
+		TimeOnTarget:    TimeOnTargetToGraphQL(countdown.TimeOnTarget),
+		At:              countdown.At,
+		SecondsToImpact: countdown.TimeOnTarget.ImpactAt().Sub(countdown.At).Seconds(),
+		FiringWeaponIds: firingWeaponIds,
+	}
+}
+
+func DangerCloseWarningToGraphQL(warning *session2.DangerCloseWarning) *model.DangerCloseWarning {
+	if warning == nil {
+		return nil
+	}
+
+	return &model.DangerCloseWarning{
+		Target:            TargetToGraphQL(warning.Target),
+		User:              UserToGraphQL(warning.User),
+		Distance:          warning.Distance,
+		DangerCloseRadius: warning.Radius,
+	}
+}
+
+func DangerCloseWarningsToGraphQL(warnings []session2.DangerCloseWarning) []*model.DangerCloseWarning {
+	result := make([]*model.DangerCloseWarning, 0, len(warnings))
+	for i := range warnings {
+		result = append(result, DangerCloseWarningToGraphQL(&warnings[i]))
+	}
+
+	return result
+}
+
+func ShotToGraphQL(shot *session2.Shot) *model.Shot {
+	if shot == nil {
+		return nil
+	}
+
+	position := shot.Impact.Position
+
+	return &model.Shot{
+		Weapon:         WeaponToGraphQL(shot.Weapon),
+		Ammunition:     AmmunitionToGraphQL(shot.Ammunition),
+		FiredBy:        UserToGraphQL(shot.FiredBy),
+		Target:         TargetToGraphQL(shot.Target),
+		ImpactPosition: &position,
+		TimeOfFlight:   shot.Impact.TimeOfFlight,
+		FiredAt:        shot.FiredAt,
+		ImpactAt:       shot.ImpactAt,
+	}
+}
+
+func PlannedTargetToGraphQL(plannedTarget session2.PlannedTarget) *model.PlannedTarget {
+	return &model.PlannedTarget{
+		Target:       TargetToGraphQL(plannedTarget.Target),
+		Solution:     FiringSolutionToGraphQL(plannedTarget.Solution, nil),
+		EngagedAfter: plannedTarget.EngagedAfter.Seconds(),
+	}
+}
+
+func WeaponAssignmentToGraphQL(weaponAssignment session2.WeaponAssignment) *model.WeaponAssignment {
+	return &model.WeaponAssignment{
+		Weapon:  WeaponToGraphQL(weaponAssignment.Weapon),
+		Targets: slice.Map(weaponAssignment.Targets, PlannedTargetToGraphQL),
+	}
+}
+
+func PlanToGraphQL(plan *session2.Plan) *model.AssignmentPlan {
+	if plan == nil {
+		return nil
+	}
+
+	return &model.AssignmentPlan{
+		Assignments:       slice.Map(plan.Assignments, WeaponAssignmentToGraphQL),
+		Unassigned:        slice.Map(plan.Unassigned, TargetToGraphQL),
+		TotalTimeToEngage: plan.TotalTimeToEngage.Seconds(),
+		LayTime:           plan.LayTime.Seconds(),
+		CreatedAt:         plan.CreatedAt,
 	}
 }
 
@@ -23,29 +250,144 @@ func SessionToGraphQL(session session2.Session) *model.Session {
 		return nil
 	}
 
+	var hostClientGuid *string
+	if hostUuid := session.HostUuid(); hostUuid != uuid.Nil {
+		guid := hostUuid.String()
+		hostClientGuid = &guid
+	}
+
+	var gameMap *model.Map
+	if m, ok := session.Map(); ok {
+		gameMap = MapToGraphQL(&m)
+	}
+
+	var plan *model.AssignmentPlan
+	if p, ok := session.Plan(); ok {
+		plan = PlanToGraphQL(&p)
+	}
+
 	return &model.Session{
-		GUID:    session.Uuid().String(),
-		Targets: slice.Map(session.Targets(), TargetToGraphQL),
-		Users:   slice.Map(session.Users(), UserToGraphQL),
-		Weapons: slice.Map(session.Weapons(), WeaponToGraphQL),
-	}
-}
-
-func WeaponTypeToGraphQL(weaponType session2.WeaponType) model.WeaponType {
-	switch weaponType {
-	case session2.TechnicalMortarWeaponType:
-		return model.WeaponTypeTechnicalMortar
-	case session2.HellCannonWeaponType:
-		return model.WeaponTypeHellCanon
-	case session2.RocketsWeaponType:
-		return model.WeaponTypeRockets
-	case session2.StandardMortarWeaponType:
-		return model.WeaponTypeStandardMortar
+		GUID:           session.Uuid().String(),
+		HostClientGUID: hostClientGuid,
+		Host:           UserToGraphQL(session.Host()),
+		DefaultRole:    RoleToGraphQL(session.DefaultRole()),
+		Map:            gameMap,
+		Targets:        slice.Map(session.Targets(), TargetToGraphQL),
+		Users:          slice.Map(session.Users(), UserToGraphQL),
+		Weapons:        slice.Map(session.Weapons(), WeaponToGraphQL),
+		FireMissions:   slice.Map(session.FireMissions(), FireMissionToGraphQL),
+		TimeOnTargets:  slice.Map(session.TimeOnTargets(), TimeOnTargetToGraphQL),
+		AssignmentPlan: plan,
+		Effects:        EffectsToGraphQL(session.Effects()),
+
+		DangerCloseWarnings:  DangerCloseWarningsToGraphQL(session.DangerCloseWarnings()),
+		BlockDangerCloseFire: session.BlockDangerCloseFire(),
+	}
+}
+
+func MapToGraphQL(m *gamemap.Map) *model.Map {
+	if m == nil {
+		return nil
+	}
+
+	return &model.Map{
+		ID:       m.Id,
+		Name:     m.Name,
+		Width:    float64(m.Width),
+		Height:   float64(m.Height),
+		GridSize: float64(m.GridSize),
+		Origin:   &m.Origin,
+	}
+}
+
+func AmmunitionKindToGraphQL(kind armory.AmmunitionKind) model.AmmunitionKind {
+	switch kind {
+	case armory.SmokeAmmunitionKind:
+		return model.AmmunitionKindSmoke
+	case armory.IlluminationAmmunitionKind:
+		return model.AmmunitionKindIllumination
+	case armory.IncendiaryAmmunitionKind:
+		return model.AmmunitionKindIncendiary
 	default:
-		return model.WeaponTypeStandardMortar
+		return model.AmmunitionKindHe
+	}
+}
+
+func AmmunitionToGraphQL(ammunition armory.Ammunition) *model.Ammunition {
+	return &model.Ammunition{
+		ID:             ammunition.Id,
+		Name:           ammunition.Name,
+		Kind:           AmmunitionKindToGraphQL(ammunition.Kind),
+		Velocity:       ammunition.Velocity,
+		Gravity:        ammunition.Gravity,
+		MinRange:       ammunition.MinRange,
+		MaxRange:       ammunition.MaxRange,
+		EffectRadius:   ammunition.EffectRadius,
+		EffectLifetime: ammunition.EffectLifetime,
 	}
 }
 
+func EffectToGraphQL(effect *session2.Effect) *model.Effect {
+	if effect == nil {
+		return nil
+	}
+
+	position := effect.Position
+
+	return &model.Effect{
+		ID:        int(effect.Id),
+		Kind:      AmmunitionKindToGraphQL(effect.Kind),
+		WeaponID:  int(effect.WeaponId),
+		Position:  &position,
+		Radius:    effect.Radius,
+		SpawnedAt: effect.SpawnedAt,
+		ExpiresAt: effect.ExpiresAt,
+	}
+}
+
+func EffectsToGraphQL(effects []session2.Effect) []*model.Effect {
+	result := make([]*model.Effect, 0, len(effects))
+	for i := range effects {
+		result = append(result, EffectToGraphQL(&effects[i]))
+	}
+
+	return result
+}
+
+func WeaponTypeToGraphQL(weaponType armory.WeaponType) *model.WeaponType {
+	var faction *string
+	if weaponType.Faction != "" {
+		faction = &weaponType.Faction
+	}
+
+	return &model.WeaponType{
+		ID:            weaponType.Id,
+		Name:          weaponType.Name,
+		Faction:       faction,
+		Velocity:      weaponType.Velocity,
+		Gravity:       weaponType.Gravity,
+		MinRange:      weaponType.MinRange,
+		MaxRange:      weaponType.MaxRange,
+		MinElevation:  weaponType.MinElevation,
+		MaxElevation:  weaponType.MaxElevation,
+		HighAngle:     weaponType.HighAngle,
+		MilsPerCircle: weaponType.MilsPerCircle,
+		Dispersion:    weaponType.Dispersion,
+		BlastRadius:   weaponType.BlastRadius,
+		TraverseArc:   weaponType.TraverseArc,
+		Ammunition:    slice.Map(weaponType.Ammunition, AmmunitionToGraphQL),
+	}
+}
+
+// WeaponsToGraphQL converts the weapons, which may be nil if they have not changed.
+func WeaponsToGraphQL(weapons []session2.Weapon) []*model.Weapon {
+	if weapons == nil {
+		return nil
+	}
+
+	return slice.Map(weapons, WeaponToGraphQL)
+}
+
 func WeaponToGraphQL(weapon session2.Weapon) *model.Weapon {
 	if weapon == nil {
 		return nil
@@ -54,12 +396,26 @@ func WeaponToGraphQL(weapon session2.Weapon) *model.Weapon {
 	position := weapon.Position()
 
 	return &model.Weapon{
-		ID:       int(weapon.Id()),
-		Active:   weapon.Active(),
-		IsOwned:  weapon.IsOwned(),
-		Owner:    UserToGraphQL(weapon.Owner()),
-		Position: &position,
-		Type:     WeaponTypeToGraphQL(weapon.Type()),
+		ID:                  int(weapon.Id()),
+		Active:              weapon.Active(),
+		IsOwned:             weapon.IsOwned(),
+		Owner:               UserToGraphQL(weapon.Owner()),
+		LeaseExpiresAt:      TimeToGraphQL(weapon.LeaseExpiresAt()),
+		HandoverRequestedBy: UserToGraphQL(weapon.HandoverRequester()),
+		Position:            &position,
+		Type:                weapon.Type().Id,
+		WeaponType:          WeaponTypeToGraphQL(weapon.Type()),
+		Ammunition:          AmmunitionToGraphQL(weapon.Ammunition()),
+		Sector:              SectorToGraphQL(weapon.Sector(), position, weapon.Profile()),
+		Solutions:           slice.Map(weapon.Solutions(), TargetSolutionToGraphQL),
+		PredictedImpact:     ImpactPredictionToGraphQL(weapon.PredictedImpact()),
+	}
+}
+
+func TargetSolutionToGraphQL(targetSolution session2.TargetSolution) *model.TargetSolution {
+	return &model.TargetSolution{
+		TargetID: int(targetSolution.TargetId),
+		Solution: FiringSolutionToGraphQL(targetSolution.Solution, targetSolution.Clearance),
 	}
 }
 
@@ -68,50 +424,300 @@ func TargetToGraphQL(target session2.Target) *model.Target {
 		return nil
 	}
 
+	var parentId *int
+	if id, ok := target.ParentId(); ok {
+		v := int(id)
+		parentId = &v
+	}
+
 	position := target.Position()
 
 	return &model.Target{
-		ID:       int(target.Id()),
-		Active:   target.Active(),
-		IsOwned:  target.IsOwned(),
-		Owner:    UserToGraphQL(target.Owner()),
-		Position: &position,
+		ID:                  int(target.Id()),
+		ParentID:            parentId,
+		Active:              target.Active(),
+		IsOwned:             target.IsOwned(),
+		Owner:               UserToGraphQL(target.Owner()),
+		LeaseExpiresAt:      TimeToGraphQL(target.LeaseExpiresAt()),
+		HandoverRequestedBy: UserToGraphQL(target.HandoverRequester()),
+		Position:            &position,
 	}
 }
 
 func SessionChangeToGraphQL(sessionChange *session2.SessionChange) *model.SessionUpdate {
 	return &model.SessionUpdate{
+		Timestamp: sessionChange.Timestamp,
+
 		UserJoined:    UserToGraphQL(sessionChange.UserJoined),
 		UserLeft:      UserToGraphQL(sessionChange.UserLeft),
 		UserChanged:   UserToGraphQL(sessionChange.UserChanged),
+		UserKicked:    UserToGraphQL(sessionChange.UserKicked),
+		UserBanned:    UserToGraphQL(sessionChange.UserBanned),
+		HostChanged:   UserToGraphQL(sessionChange.HostChanged),
 		TargetAdded:   TargetToGraphQL(sessionChange.TargetAdded),
 		TargetChanged: TargetToGraphQL(sessionChange.TargetChanged),
 		TargetRemoved: TargetToGraphQL(sessionChange.TargetRemoved),
 		WeaponAdded:   WeaponToGraphQL(sessionChange.WeaponAdded),
 		WeaponChanged: WeaponToGraphQL(sessionChange.WeaponChanged),
 		WeaponRemoved: WeaponToGraphQL(sessionChange.WeaponRemoved),
+
+		WeaponSolutionsChanged: WeaponsToGraphQL(sessionChange.WeaponSolutionsChanged),
+
+		FireMissionAdded:   FireMissionToGraphQL(sessionChange.FireMissionAdded),
+		FireMissionChanged: FireMissionToGraphQL(sessionChange.FireMissionChanged),
+		FireMissionRemoved: FireMissionToGraphQL(sessionChange.FireMissionRemoved),
+
+		TimeOnTargetScheduled: TimeOnTargetToGraphQL(sessionChange.TimeOnTargetScheduled),
+		TimeOnTargetCountdown: TimeOnTargetCountdownToGraphQL(sessionChange.TimeOnTargetCountdown),
+		TimeOnTargetEnded:     TimeOnTargetToGraphQL(sessionChange.TimeOnTargetEnded),
+
+		ShotFired: ShotToGraphQL(sessionChange.ShotFired),
+
+		EffectAdded:   EffectToGraphQL(sessionChange.EffectAdded),
+		EffectRemoved: EffectToGraphQL(sessionChange.EffectRemoved),
+
+		DangerCloseRaised:  DangerCloseWarningToGraphQL(sessionChange.DangerCloseRaised),
+		DangerCloseCleared: DangerCloseWarningToGraphQL(sessionChange.DangerCloseCleared),
+
+		AssignmentPlanChanged: PlanToGraphQL(sessionChange.PlanChanged),
+
+		MapChanged: MapToGraphQL(sessionChange.MapChanged),
+
+		SessionClosed: SessionGuidToGraphQL(sessionChange.SessionClosed),
 	}
 }
 
-func WeaponTypeFromGraphQL(weaponType model.WeaponType) session2.WeaponType {
-	switch weaponType {
-	case model.WeaponTypeTechnicalMortar:
-		return session2.TechnicalMortarWeaponType
-	case model.WeaponTypeHellCanon:
-		return session2.HellCannonWeaponType
-	case model.WeaponTypeRockets:
-		return session2.RocketsWeaponType
-	case model.WeaponTypeStandardMortar:
-		return session2.StandardMortarWeaponType
-	default:
-		return session2.StandardMortarWeaponType
+func SessionGuidToGraphQL(session session2.Session) *string {
+	if session == nil {
+		return nil
 	}
+
+	guid := session.Uuid().String()
+
+	return &guid
 }
 
-func Vector3InputFromGraphQL(vector3Input model.Vector3Input) math.Vector3 {
-	return math.Vector3{
+func TimeToGraphQL(t time.Time, ok bool) *time.Time {
+	if !ok {
+		return nil
+	}
+
+	return &t
+}
+
+// DurationFromGraphQL converts seconds into a duration. A missing value results in a zero duration.
+func DurationFromGraphQL(seconds *float64) time.Duration {
+	if seconds == nil {
+		return 0
+	}
+
+	return time.Duration(*seconds * float64(time.Second))
+}
+
+// Vector3InputFromGraphQL converts the input into a vector. An omitted height is resolved from the
+// heightmap if there is one and the position lies on it, otherwise it is 0.
+func Vector3InputFromGraphQL(vector3Input model.Vector3Input, heightmap terrain.Heightmap) math.Vector3 {
+	v := math.Vector3{
 		X: float32(vector3Input.X),
 		Y: float32(vector3Input.Y),
-		Z: float32(vector3Input.Z),
 	}
+
+	if vector3Input.Z != nil {
+		v.Z = float32(*vector3Input.Z)
+	} else if heightmap != nil {
+		v.Z, _ = heightmap.Height(v)
+	}
+
+	return v
+}
+
+// GridRefFromGraphQL converts a keypad grid reference of the map into the center of the referenced
+// cell. Its height is resolved from the heightmap if there is one.
+func GridRefFromGraphQL(gridRef string, m gamemap.Map, heightmap terrain.Heightmap) (math.Vector3, error) {
+	v, err := m.Grid().Parse(gridRef)
+	if err != nil {
+		return math.Vector3{}, err
+	}
+
+	if heightmap != nil {
+		v.Z, _ = heightmap.Height(v)
+	}
+
+	return v, nil
+}
+
+func FiringSolutionStatusToGraphQL(status ballistics.Status) model.FiringSolutionStatus {
+	switch status {
+	case ballistics.TooClose:
+		return model.FiringSolutionStatusTooClose
+	case ballistics.TooFar:
+		return model.FiringSolutionStatusTooFar
+	case ballistics.InRange:
+		return model.FiringSolutionStatusInRange
+	case ballistics.OutOfArc:
+		return model.FiringSolutionStatusOutOfArc
+	default:
+		return model.FiringSolutionStatusInRange
+	}
+}
+
+// AngleFromGraphQL converts an angle into radians. A missing unit defaults to the given one.
+func AngleFromGraphQL(value float64, unit *model.AngleUnit, defaultUnit model.AngleUnit, milsPerCircle float64) float64 {
+	if unit != nil {
+		defaultUnit = *unit
+	}
+
+	if defaultUnit == model.AngleUnitDegrees {
+		return ballistics.RadiansFromDegrees(value)
+	}
+
+	return ballistics.RadiansFromMils(value, milsPerCircle)
+}
+
+func FirePatternTypeFromGraphQL(patternType model.FirePatternType) pattern.Type {
+	switch patternType {
+	case model.FirePatternTypeParallelSheaf:
+		return pattern.ParallelSheaf
+	case model.FirePatternTypeLinearSheaf:
+		return pattern.LinearSheaf
+	case model.FirePatternTypeCircle:
+		return pattern.Circle
+	case model.FirePatternTypeCreepingBarrage:
+		return pattern.CreepingBarrage
+	default:
+		return pattern.ParallelSheaf
+	}
+}
+
+// FirePatternFromGraphQL converts the input into a pattern. Omitted values fall back to the
+// defaults of the schema.
+func FirePatternFromGraphQL(input model.FirePatternInput) pattern.Pattern {
+	p := pattern.Pattern{
+		Type:         FirePatternTypeFromGraphQL(input.Type),
+		Points:       3,
+		Spacing:      25,
+		Radius:       50,
+		Steps:        3,
+		StepDistance: 50,
+	}
+
+	if input.Points != nil {
+		p.Points = *input.Points
+	}
+	if input.Spacing != nil {
+		p.Spacing = *input.Spacing
+	}
+	if input.Bearing != nil {
+		p.Bearing = AngleFromGraphQL(*input.Bearing, input.Unit, model.AngleUnitDegrees, milsPerCircle)
+	}
+	if input.Radius != nil {
+		p.Radius = *input.Radius
+	}
+	if input.Steps != nil {
+		p.Steps = *input.Steps
+	}
+	if input.StepDistance != nil {
+		p.StepDistance = *input.StepDistance
+	}
+
+	return p
+}
+
+func AimPointToGraphQL(aimPoint pattern.AimPoint) *model.AimPoint {
+	position := aimPoint.Position
+
+	return &model.AimPoint{
+		Position: &position,
+		Step:     aimPoint.Step,
+	}
+}
+
+func ImpactPredictionToGraphQL(prediction *terrain.Prediction) *model.ImpactPrediction {
+	if prediction == nil {
+		return nil
+	}
+
+	position := prediction.Impact.Position
+
+	return &model.ImpactPrediction{
+		Status:             FiringSolutionStatusToGraphQL(prediction.Solution.Status),
+		ElevationMils:      prediction.Solution.ElevationMils(),
+		ElevationDegrees:   prediction.Solution.ElevationDegrees(),
+		AzimuthMils:        prediction.Solution.AzimuthMils(),
+		AzimuthDegrees:     prediction.Solution.AzimuthDegrees(),
+		Position:           &position,
+		HorizontalDistance: prediction.Impact.HorizontalDistance,
+		TimeOfFlight:       prediction.Impact.TimeOfFlight,
+		TerrainIntersected: prediction.TerrainIntersected,
+	}
+}
+
+// dispersionPolygonPoints is the number of points of the dispersion ellipse and danger close circle.
+const dispersionPolygonPoints = 32
+
+func Vector3ToGraphQL(v math.Vector3) *math.Vector3 {
+	return &v
+}
+
+func DispersionToGraphQL(dispersion ballistics.Dispersion) *model.Dispersion {
+	return &model.Dispersion{
+		RangeRadius:       dispersion.RangeRadius,
+		DeflectionRadius:  dispersion.DeflectionRadius,
+		DangerCloseRadius: dispersion.DangerCloseRadius,
+		Ellipse:           slice.Map(dispersion.Ellipse(dispersionPolygonPoints), Vector3ToGraphQL),
+		DangerClose:       slice.Map(dispersion.DangerClose(dispersionPolygonPoints), Vector3ToGraphQL),
+	}
+}
+
+// sectorPolygonPoints is the number of points of the outer arc of a sector.
+const sectorPolygonPoints = 32
+
+func SectorToGraphQL(sector ballistics.Sector, position math.Vector3, profile ballistics.Profile) *model.Sector {
+	return &model.Sector{
+		HeadingDegrees:     ballistics.Degrees(sector.Heading),
+		HeadingMils:        ballistics.Mils(sector.Heading, profile.MilsPerCircle),
+		TraverseArcDegrees: ballistics.Degrees(sector.Arc),
+		TraverseArcMils:    ballistics.Mils(sector.Arc, profile.MilsPerCircle),
+		Restricted:         sector.Restricted(),
+		Polygon:            slice.Map(sector.Polygon(position, profile, sectorPolygonPoints), Vector3ToGraphQL),
+	}
+}
+
+// FiringSolutionToGraphQL converts the solution and its terrain clearance, which may be nil if unknown.
+func FiringSolutionToGraphQL(solution ballistics.Solution, clearance *terrain.Clearance) *model.FiringSolution {
+	firingSolution := &model.FiringSolution{
+		Status:             FiringSolutionStatusToGraphQL(solution.Status),
+		InRange:            solution.InRange(),
+		AzimuthMils:        solution.AzimuthMils(),
+		AzimuthDegrees:     solution.AzimuthDegrees(),
+		HorizontalDistance: solution.HorizontalDistance,
+		HeightDelta:        solution.HeightDelta,
+		MinRange:           solution.Profile.MinRange,
+		MaxRange:           solution.Profile.MaxRange,
+	}
+
+	// A solution out of the arc has been solved like one in range, so that the weapon can be
+	// turned towards the target.
+	if solution.InRange() || solution.Status == ballistics.OutOfArc {
+		elevationMils := solution.ElevationMils()
+		elevationDegrees := solution.ElevationDegrees()
+		timeOfFlight := solution.TimeOfFlight
+
+		firingSolution.ElevationMils = &elevationMils
+		firingSolution.ElevationDegrees = &elevationDegrees
+		firingSolution.TimeOfFlight = &timeOfFlight
+		firingSolution.Dispersion = DispersionToGraphQL(solution.Dispersion)
+	}
+
+	if clearance != nil {
+		trajectoryClear := clearance.Clear
+		firingSolution.TrajectoryClear = &trajectoryClear
+
+		if !clearance.Clear {
+			obstruction := clearance.Obstruction
+			firingSolution.ObstructionAt = &obstruction
+		}
+	}
+
+	return firingSolution
 }
@@ -0,0 +1,14 @@
+package graphql
+
+import "errors"
+
+var ErrUserNotInSession = errors.New("user is not in session")
+var ErrUserNotHost = errors.New("user is not the host of the session")
+var ErrRoleOfHost = errors.New("the role of the host cannot be changed")
+var ErrPermissionDenied = errors.New("permission denied")
+var ErrPositionOutOfBounds = errors.New("position is outside of the map bounds")
+var ErrAmbiguousPosition = errors.New("only one of a position, a grid reference or a polar position may be given")
+var ErrAmbiguousReference = errors.New("exactly one reference point has to be given")
+var ErrSessionHasNoMap = errors.New("session has no map")
+var ErrNoGunTargetLine = errors.New("weapon and target share the same position")
+var ErrMissingPosition = errors.New("either a position or a grid reference has to be given")
@@ -4,111 +4,627 @@ package model
 
 import (
 	"fmt"
-	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
 	"io"
 	"strconv"
+	"time"
+
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
 )
 
+type AimPoint struct {
+	Position *math.Vector3 `json:"position"`
+	Step     int           `json:"step"`
+}
+
+type Ammunition struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Kind           AmmunitionKind `json:"kind"`
+	Velocity       float64        `json:"velocity"`
+	Gravity        float64        `json:"gravity"`
+	MinRange       float64        `json:"minRange"`
+	MaxRange       float64        `json:"maxRange"`
+	EffectRadius   float64        `json:"effectRadius"`
+	EffectLifetime float64        `json:"effectLifetime"`
+}
+
+type AssignmentPlan struct {
+	Assignments       []*WeaponAssignment `json:"assignments"`
+	Unassigned        []*Target           `json:"unassigned"`
+	TotalTimeToEngage float64             `json:"totalTimeToEngage"`
+	LayTime           float64             `json:"layTime"`
+	CreatedAt         time.Time           `json:"createdAt"`
+}
+
+type DangerCloseWarning struct {
+	Target            *Target `json:"target"`
+	User              *User   `json:"user"`
+	Distance          float64 `json:"distance"`
+	DangerCloseRadius float64 `json:"dangerCloseRadius"`
+}
+
+type Dispersion struct {
+	RangeRadius       float64         `json:"rangeRadius"`
+	DeflectionRadius  float64         `json:"deflectionRadius"`
+	DangerCloseRadius float64         `json:"dangerCloseRadius"`
+	Ellipse           []*math.Vector3 `json:"ellipse"`
+	DangerClose       []*math.Vector3 `json:"dangerClose"`
+}
+
+type Effect struct {
+	ID        int            `json:"id"`
+	Kind      AmmunitionKind `json:"kind"`
+	WeaponID  int            `json:"weaponId"`
+	Position  *math.Vector3  `json:"position"`
+	Radius    float64        `json:"radius"`
+	SpawnedAt time.Time      `json:"spawnedAt"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+type FireMission struct {
+	ID             int              `json:"id"`
+	State          FireMissionState `json:"state"`
+	Rounds         int              `json:"rounds"`
+	Targets        []*Target        `json:"targets"`
+	Weapons        []*Weapon        `json:"weapons"`
+	RequestedBy    *User            `json:"requestedBy"`
+	RequestedAt    time.Time        `json:"requestedAt"`
+	AcknowledgedBy *User            `json:"acknowledgedBy"`
+}
+
+type FirePatternInput struct {
+	Type         FirePatternType `json:"type"`
+	Points       *int            `json:"points"`
+	Spacing      *float64        `json:"spacing"`
+	Bearing      *float64        `json:"bearing"`
+	Unit         *AngleUnit      `json:"unit"`
+	Radius       *float64        `json:"radius"`
+	Steps        *int            `json:"steps"`
+	StepDistance *float64        `json:"stepDistance"`
+}
+
+type FiringSolution struct {
+	Status             FiringSolutionStatus `json:"status"`
+	InRange            bool                 `json:"inRange"`
+	ElevationMils      *float64             `json:"elevationMils"`
+	ElevationDegrees   *float64             `json:"elevationDegrees"`
+	AzimuthMils        float64              `json:"azimuthMils"`
+	AzimuthDegrees     float64              `json:"azimuthDegrees"`
+	HorizontalDistance float64              `json:"horizontalDistance"`
+	HeightDelta        float64              `json:"heightDelta"`
+	TimeOfFlight       *float64             `json:"timeOfFlight"`
+	MinRange           float64              `json:"minRange"`
+	MaxRange           float64              `json:"maxRange"`
+	TrajectoryClear    *bool                `json:"trajectoryClear"`
+	ObstructionAt      *math.Vector3        `json:"obstructionAt"`
+	Dispersion         *Dispersion          `json:"dispersion"`
+}
+
+type ImpactPrediction struct {
+	Status             FiringSolutionStatus `json:"status"`
+	ElevationMils      float64              `json:"elevationMils"`
+	ElevationDegrees   float64              `json:"elevationDegrees"`
+	AzimuthMils        float64              `json:"azimuthMils"`
+	AzimuthDegrees     float64              `json:"azimuthDegrees"`
+	Position           *math.Vector3        `json:"position"`
+	HorizontalDistance float64              `json:"horizontalDistance"`
+	TimeOfFlight       float64              `json:"timeOfFlight"`
+	TerrainIntersected bool                 `json:"terrainIntersected"`
+}
+
+type Map struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Width    float64       `json:"width"`
+	Height   float64       `json:"height"`
+	GridSize float64       `json:"gridSize"`
+	Origin   *math.Vector3 `json:"origin"`
+}
+
+type PlannedTarget struct {
+	Target       *Target         `json:"target"`
+	Solution     *FiringSolution `json:"solution"`
+	EngagedAfter float64         `json:"engagedAfter"`
+}
+
+type PolarInput struct {
+	Bearing      float64       `json:"bearing"`
+	Distance     float64       `json:"distance"`
+	Elevation    *float64      `json:"elevation"`
+	Unit         *AngleUnit    `json:"unit"`
+	From         *Vector3Input `json:"from"`
+	FromGridRef  *string       `json:"fromGridRef"`
+	FromTargetID *int          `json:"fromTargetId"`
+	FromWeaponID *int          `json:"fromWeaponId"`
+}
+
+type Sector struct {
+	HeadingDegrees     float64         `json:"headingDegrees"`
+	HeadingMils        float64         `json:"headingMils"`
+	TraverseArcDegrees float64         `json:"traverseArcDegrees"`
+	TraverseArcMils    float64         `json:"traverseArcMils"`
+	Restricted         bool            `json:"restricted"`
+	Polygon            []*math.Vector3 `json:"polygon"`
+}
+
 type Session struct {
-	GUID    string    `json:"guid"`
-	Users   []*User   `json:"users"`
-	Weapons []*Weapon `json:"weapons"`
-	Targets []*Target `json:"targets"`
+	GUID                 string                `json:"guid"`
+	HostClientGUID       *string               `json:"hostClientGuid"`
+	Host                 *User                 `json:"host"`
+	DefaultRole          Role                  `json:"defaultRole"`
+	Map                  *Map                  `json:"map"`
+	Users                []*User               `json:"users"`
+	Weapons              []*Weapon             `json:"weapons"`
+	Targets              []*Target             `json:"targets"`
+	FireMissions         []*FireMission        `json:"fireMissions"`
+	TimeOnTargets        []*TimeOnTarget       `json:"timeOnTargets"`
+	AssignmentPlan       *AssignmentPlan       `json:"assignmentPlan"`
+	Effects              []*Effect             `json:"effects"`
+	DangerCloseWarnings  []*DangerCloseWarning `json:"dangerCloseWarnings"`
+	BlockDangerCloseFire bool                  `json:"blockDangerCloseFire"`
 }
 
 type SessionUpdate struct {
-	UserLeft      *User   `json:"userLeft"`
-	UserJoined    *User   `json:"userJoined"`
-	UserChanged   *User   `json:"userChanged"`
-	TargetAdded   *Target `json:"targetAdded"`
-	TargetChanged *Target `json:"targetChanged"`
-	TargetRemoved *Target `json:"targetRemoved"`
-	WeaponAdded   *Weapon `json:"weaponAdded"`
-	WeaponChanged *Weapon `json:"weaponChanged"`
-	WeaponRemoved *Weapon `json:"weaponRemoved"`
+	Timestamp              time.Time              `json:"timestamp"`
+	UserLeft               *User                  `json:"userLeft"`
+	UserJoined             *User                  `json:"userJoined"`
+	UserChanged            *User                  `json:"userChanged"`
+	UserKicked             *User                  `json:"userKicked"`
+	UserBanned             *User                  `json:"userBanned"`
+	HostChanged            *User                  `json:"hostChanged"`
+	TargetAdded            *Target                `json:"targetAdded"`
+	TargetChanged          *Target                `json:"targetChanged"`
+	TargetRemoved          *Target                `json:"targetRemoved"`
+	WeaponAdded            *Weapon                `json:"weaponAdded"`
+	WeaponChanged          *Weapon                `json:"weaponChanged"`
+	WeaponRemoved          *Weapon                `json:"weaponRemoved"`
+	WeaponSolutionsChanged []*Weapon              `json:"weaponSolutionsChanged"`
+	FireMissionAdded       *FireMission           `json:"fireMissionAdded"`
+	FireMissionChanged     *FireMission           `json:"fireMissionChanged"`
+	FireMissionRemoved     *FireMission           `json:"fireMissionRemoved"`
+	TimeOnTargetScheduled  *TimeOnTarget          `json:"timeOnTargetScheduled"`
+	TimeOnTargetCountdown  *TimeOnTargetCountdown `json:"timeOnTargetCountdown"`
+	TimeOnTargetEnded      *TimeOnTarget          `json:"timeOnTargetEnded"`
+	ShotFired              *Shot                  `json:"shotFired"`
+	EffectAdded            *Effect                `json:"effectAdded"`
+	EffectRemoved          *Effect                `json:"effectRemoved"`
+	DangerCloseRaised      *DangerCloseWarning    `json:"dangerCloseRaised"`
+	DangerCloseCleared     *DangerCloseWarning    `json:"dangerCloseCleared"`
+	AssignmentPlanChanged  *AssignmentPlan        `json:"assignmentPlanChanged"`
+	MapChanged             *Map                   `json:"mapChanged"`
+	SessionClosed          *string                `json:"sessionClosed"`
+}
+
+type Shot struct {
+	Weapon         *Weapon       `json:"weapon"`
+	Ammunition     *Ammunition   `json:"ammunition"`
+	FiredBy        *User         `json:"firedBy"`
+	Target         *Target       `json:"target"`
+	ImpactPosition *math.Vector3 `json:"impactPosition"`
+	TimeOfFlight   float64       `json:"timeOfFlight"`
+	FiredAt        time.Time     `json:"firedAt"`
+	ImpactAt       time.Time     `json:"impactAt"`
 }
 
 type Target struct {
-	ID       int           `json:"id"`
-	Position *math.Vector3 `json:"position"`
-	Active   bool          `json:"active"`
-	Owner    *User         `json:"owner"`
-	IsOwned  bool          `json:"isOwned"`
+	ID                  int           `json:"id"`
+	ParentID            *int          `json:"parentId"`
+	Position            *math.Vector3 `json:"position"`
+	Active              bool          `json:"active"`
+	Owner               *User         `json:"owner"`
+	IsOwned             bool          `json:"isOwned"`
+	LeaseExpiresAt      *time.Time    `json:"leaseExpiresAt"`
+	HandoverRequestedBy *User         `json:"handoverRequestedBy"`
 }
 
 type TargetInput struct {
 	ID       int           `json:"id"`
 	Position *Vector3Input `json:"position"`
+	GridRef  *string       `json:"gridRef"`
+	Polar    *PolarInput   `json:"polar"`
 	Active   *bool         `json:"active"`
 }
 
+type TargetSolution struct {
+	TargetID int             `json:"targetId"`
+	Solution *FiringSolution `json:"solution"`
+}
+
+type TimeOnTarget struct {
+	ID          int                 `json:"id"`
+	State       TimeOnTargetState   `json:"state"`
+	Target      *Target             `json:"target"`
+	Shots       []*TimeOnTargetShot `json:"shots"`
+	ImpactAt    time.Time           `json:"impactAt"`
+	RequestedBy *User               `json:"requestedBy"`
+}
+
+type TimeOnTargetCountdown struct {
+	TimeOnTarget    *TimeOnTarget `json:"timeOnTarget"`
+	At              time.Time     `json:"at"`
+	SecondsToImpact float64       `json:"secondsToImpact"`
+	FiringWeaponIds []int         `json:"firingWeaponIds"`
+}
+
+type TimeOnTargetShot struct {
+	Weapon   *Weapon         `json:"weapon"`
+	Solution *FiringSolution `json:"solution"`
+	FireAt   time.Time       `json:"fireAt"`
+}
+
 type User struct {
-	ClientGUID string `json:"clientGuid"`
-	Name       string `json:"name"`
+	ClientGUID        string        `json:"clientGuid"`
+	Name              string        `json:"name"`
+	Role              Role          `json:"role"`
+	Position          *math.Vector3 `json:"position"`
+	PositionExpiresAt *time.Time    `json:"positionExpiresAt"`
 }
 
 type Vector3Input struct {
-	X float64 `json:"x"`
-	Y float64 `json:"y"`
-	Z float64 `json:"z"`
+	X float64  `json:"x"`
+	Y float64  `json:"y"`
+	Z *float64 `json:"z"`
 }
 
 type Weapon struct {
-	ID       int           `json:"id"`
-	Type     WeaponType    `json:"type"`
-	Position *math.Vector3 `json:"position"`
-	Active   bool          `json:"active"`
-	Owner    *User         `json:"owner"`
-	IsOwned  bool          `json:"isOwned"`
+	ID                  int               `json:"id"`
+	Type                string            `json:"type"`
+	WeaponType          *WeaponType       `json:"weaponType"`
+	Ammunition          *Ammunition       `json:"ammunition"`
+	Sector              *Sector           `json:"sector"`
+	Position            *math.Vector3     `json:"position"`
+	Active              bool              `json:"active"`
+	Owner               *User             `json:"owner"`
+	IsOwned             bool              `json:"isOwned"`
+	LeaseExpiresAt      *time.Time        `json:"leaseExpiresAt"`
+	HandoverRequestedBy *User             `json:"handoverRequestedBy"`
+	Solutions           []*TargetSolution `json:"solutions"`
+	PredictedImpact     *ImpactPrediction `json:"predictedImpact"`
+}
+
+type WeaponAssignment struct {
+	Weapon  *Weapon          `json:"weapon"`
+	Targets []*PlannedTarget `json:"targets"`
 }
 
 type WeaponInput struct {
-	ID       int           `json:"id"`
-	Position *Vector3Input `json:"position"`
-	Active   *bool         `json:"active"`
+	ID          int           `json:"id"`
+	Position    *Vector3Input `json:"position"`
+	GridRef     *string       `json:"gridRef"`
+	Active      *bool         `json:"active"`
+	Ammunition  *string       `json:"ammunition"`
+	Heading     *float64      `json:"heading"`
+	TraverseArc *float64      `json:"traverseArc"`
+	Unit        *AngleUnit    `json:"unit"`
+}
+
+type WeaponType struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Faction       *string       `json:"faction"`
+	Velocity      float64       `json:"velocity"`
+	Gravity       float64       `json:"gravity"`
+	MinRange      float64       `json:"minRange"`
+	MaxRange      float64       `json:"maxRange"`
+	MinElevation  float64       `json:"minElevation"`
+	MaxElevation  float64       `json:"maxElevation"`
+	HighAngle     bool          `json:"highAngle"`
+	MilsPerCircle float64       `json:"milsPerCircle"`
+	Dispersion    float64       `json:"dispersion"`
+	BlastRadius   float64       `json:"blastRadius"`
+	TraverseArc   float64       `json:"traverseArc"`
+	Ammunition    []*Ammunition `json:"ammunition"`
+}
+
+type AmmunitionKind string
+
+const (
+	AmmunitionKindHe           AmmunitionKind = "HE"
+	AmmunitionKindSmoke        AmmunitionKind = "Smoke"
+	AmmunitionKindIllumination AmmunitionKind = "Illumination"
+	AmmunitionKindIncendiary   AmmunitionKind = "Incendiary"
+)
+
+var AllAmmunitionKind = []AmmunitionKind{
+	AmmunitionKindHe,
+	AmmunitionKindSmoke,
+	AmmunitionKindIllumination,
+	AmmunitionKindIncendiary,
+}
+
+func (e AmmunitionKind) IsValid() bool {
+	switch e {
+	case AmmunitionKindHe, AmmunitionKindSmoke, AmmunitionKindIllumination, AmmunitionKindIncendiary:
+		return true
+	}
+	return false
+}
+
+func (e AmmunitionKind) String() string {
+	return string(e)
+}
+
+func (e *AmmunitionKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AmmunitionKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AmmunitionKind", str)
+	}
+	return nil
+}
+
+func (e AmmunitionKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AngleUnit string
+
+const (
+	AngleUnitMils    AngleUnit = "Mils"
+	AngleUnitDegrees AngleUnit = "Degrees"
+)
+
+var AllAngleUnit = []AngleUnit{
+	AngleUnitMils,
+	AngleUnitDegrees,
+}
+
+func (e AngleUnit) IsValid() bool {
+	switch e {
+	case AngleUnitMils, AngleUnitDegrees:
+		return true
+	}
+	return false
+}
+
+func (e AngleUnit) String() string {
+	return string(e)
+}
+
+func (e *AngleUnit) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AngleUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AngleUnit", str)
+	}
+	return nil
+}
+
+func (e AngleUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FireMissionState string
+
+const (
+	FireMissionStateRequested    FireMissionState = "Requested"
+	FireMissionStateAcknowledged FireMissionState = "Acknowledged"
+	FireMissionStateFiring       FireMissionState = "Firing"
+	FireMissionStateSplash       FireMissionState = "Splash"
+	FireMissionStateComplete     FireMissionState = "Complete"
+	FireMissionStateCancelled    FireMissionState = "Cancelled"
+)
+
+var AllFireMissionState = []FireMissionState{
+	FireMissionStateRequested,
+	FireMissionStateAcknowledged,
+	FireMissionStateFiring,
+	FireMissionStateSplash,
+	FireMissionStateComplete,
+	FireMissionStateCancelled,
+}
+
+func (e FireMissionState) IsValid() bool {
+	switch e {
+	case FireMissionStateRequested, FireMissionStateAcknowledged, FireMissionStateFiring, FireMissionStateSplash, FireMissionStateComplete, FireMissionStateCancelled:
+		return true
+	}
+	return false
+}
+
+func (e FireMissionState) String() string {
+	return string(e)
+}
+
+func (e *FireMissionState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FireMissionState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FireMissionState", str)
+	}
+	return nil
+}
+
+func (e FireMissionState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FirePatternType string
+
+const (
+	FirePatternTypeParallelSheaf   FirePatternType = "ParallelSheaf"
+	FirePatternTypeLinearSheaf     FirePatternType = "LinearSheaf"
+	FirePatternTypeCircle          FirePatternType = "Circle"
+	FirePatternTypeCreepingBarrage FirePatternType = "CreepingBarrage"
+)
+
+var AllFirePatternType = []FirePatternType{
+	FirePatternTypeParallelSheaf,
+	FirePatternTypeLinearSheaf,
+	FirePatternTypeCircle,
+	FirePatternTypeCreepingBarrage,
+}
+
+func (e FirePatternType) IsValid() bool {
+	switch e {
+	case FirePatternTypeParallelSheaf, FirePatternTypeLinearSheaf, FirePatternTypeCircle, FirePatternTypeCreepingBarrage:
+		return true
+	}
+	return false
+}
+
+func (e FirePatternType) String() string {
+	return string(e)
+}
+
+func (e *FirePatternType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FirePatternType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FirePatternType", str)
+	}
+	return nil
+}
+
+func (e FirePatternType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FiringSolutionStatus string
+
+const (
+	FiringSolutionStatusInRange  FiringSolutionStatus = "InRange"
+	FiringSolutionStatusTooClose FiringSolutionStatus = "TooClose"
+	FiringSolutionStatusTooFar   FiringSolutionStatus = "TooFar"
+	FiringSolutionStatusOutOfArc FiringSolutionStatus = "OutOfArc"
+)
+
+var AllFiringSolutionStatus = []FiringSolutionStatus{
+	FiringSolutionStatusInRange,
+	FiringSolutionStatusTooClose,
+	FiringSolutionStatusTooFar,
+	FiringSolutionStatusOutOfArc,
+}
+
+func (e FiringSolutionStatus) IsValid() bool {
+	switch e {
+	case FiringSolutionStatusInRange, FiringSolutionStatusTooClose, FiringSolutionStatusTooFar, FiringSolutionStatusOutOfArc:
+		return true
+	}
+	return false
+}
+
+func (e FiringSolutionStatus) String() string {
+	return string(e)
+}
+
+func (e *FiringSolutionStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FiringSolutionStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FiringSolutionStatus", str)
+	}
+	return nil
+}
+
+func (e FiringSolutionStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleCommander Role = "Commander"
+	RoleSpotter   Role = "Spotter"
+	RoleGunner    Role = "Gunner"
+	RoleSpectator Role = "Spectator"
+)
+
+var AllRole = []Role{
+	RoleCommander,
+	RoleSpotter,
+	RoleGunner,
+	RoleSpectator,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleCommander, RoleSpotter, RoleGunner, RoleSpectator:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-type WeaponType string
+type TimeOnTargetState string
 
 const (
-	WeaponTypeStandardMortar  WeaponType = "StandardMortar"
-	WeaponTypeTechnicalMortar WeaponType = "TechnicalMortar"
-	WeaponTypeRockets         WeaponType = "Rockets"
-	WeaponTypeHellCanon       WeaponType = "HellCanon"
+	TimeOnTargetStateScheduled TimeOnTargetState = "Scheduled"
+	TimeOnTargetStateComplete  TimeOnTargetState = "Complete"
+	TimeOnTargetStateCancelled TimeOnTargetState = "Cancelled"
 )
 
-var AllWeaponType = []WeaponType{
-	WeaponTypeStandardMortar,
-	WeaponTypeTechnicalMortar,
-	WeaponTypeRockets,
-	WeaponTypeHellCanon,
+var AllTimeOnTargetState = []TimeOnTargetState{
+	TimeOnTargetStateScheduled,
+	TimeOnTargetStateComplete,
+	TimeOnTargetStateCancelled,
 }
 
-func (e WeaponType) IsValid() bool {
+func (e TimeOnTargetState) IsValid() bool {
 	switch e {
-	case WeaponTypeStandardMortar, WeaponTypeTechnicalMortar, WeaponTypeRockets, WeaponTypeHellCanon:
+	case TimeOnTargetStateScheduled, TimeOnTargetStateComplete, TimeOnTargetStateCancelled:
 		return true
 	}
 	return false
 }
 
-func (e WeaponType) String() string {
+func (e TimeOnTargetState) String() string {
 	return string(e)
 }
 
-func (e *WeaponType) UnmarshalGQL(v interface{}) error {
+func (e *TimeOnTargetState) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = WeaponType(str)
+	*e = TimeOnTargetState(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid WeaponType", str)
+		return fmt.Errorf("%s is not a valid TimeOnTargetState", str)
 	}
 	return nil
 }
 
-func (e WeaponType) MarshalGQL(w io.Writer) {
+func (e TimeOnTargetState) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,31 @@
+package graphql
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql/model"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/pattern"
+	session2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
+)
+
+// aimPoints returns the aim points of the fire pattern around the target. Heights are resolved
+// from the heightmap of the session map, aim points outside of the map are rejected.
+func (r *Resolver) aimPoints(session session2.Session, target session2.Target, input model.FirePatternInput) ([]pattern.AimPoint, error) {
+	aimPoints, err := FirePatternFromGraphQL(input).AimPoints(target.Position())
+	if err != nil {
+		return nil, err
+	}
+
+	heightmap := r.sessionHeightmap(session)
+	for i := range aimPoints {
+		if heightmap != nil {
+			if height, ok := heightmap.Height(aimPoints[i].Position); ok {
+				aimPoints[i].Position.Z = height
+			}
+		}
+
+		if err := validatePosition(session, aimPoints[i].Position); err != nil {
+			return nil, err
+		}
+	}
+
+	return aimPoints, nil
+}
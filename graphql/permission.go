@@ -0,0 +1,59 @@
+package graphql
+
+import session2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
+
+// authorize checks whether the role of the user grants the permission.
+func authorize(user session2.User, permission session2.Permission) error {
+	if !user.Role().Can(permission) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// authorizeFireMissionState checks whether the user may move a fire mission to the state. Gunners
+// acknowledge and fire missions, spotters and gunners may complete or cancel them.
+func authorizeFireMissionState(user session2.User, state session2.FireMissionState) error {
+	switch state {
+	case session2.CompleteFireMissionState, session2.CancelledFireMissionState:
+		if err := authorize(user, session2.ManageTargetsPermission); err == nil {
+			return nil
+		}
+	}
+
+	return authorize(user, session2.ManageWeaponsPermission)
+}
+
+// authorizeOwned additionally requires the user to either own the entity, the entity to be
+// unowned or the user to be allowed to override the ownership of other users.
+func authorizeOwned(user session2.User, owner session2.User, permission session2.Permission) error {
+	if err := authorize(user, permission); err != nil {
+		return err
+	}
+
+	if owner == nil || owner.ClientUuid() == user.ClientUuid() {
+		return nil
+	}
+
+	return authorize(user, session2.OverrideOwnershipPermission)
+}
+
+// authorizeRemoveTarget checks whether the user may remove the target along with its children
+// among the targets, as children owned by other users are removed with their parent as well.
+func authorizeRemoveTarget(user session2.User, target session2.Target, targets []session2.Target) error {
+	if err := authorizeOwned(user, target.Owner(), session2.ManageTargetsPermission); err != nil {
+		return err
+	}
+
+	for _, child := range targets {
+		if parentId, ok := child.ParentId(); !ok || parentId != target.Id() {
+			continue
+		}
+
+		if err := authorizeOwned(user, child.Owner(), session2.ManageTargetsPermission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,7 +2,10 @@ package graphql
 
 import (
 	"crypto/ecdsa"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/session/storage"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
 )
 
 // This file will not be regenerated automatically.
@@ -12,4 +15,7 @@ import (
 type Resolver struct {
 	EcdsaKey       *ecdsa.PrivateKey
 	SessionStorage storage.Storage
+	MapCatalog     gamemap.Catalog
+	Heightmaps     terrain.Store
+	WeaponRegistry armory.Registry
 }
@@ -6,17 +6,19 @@ package graphql
 import (
 	"context"
 	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	auth2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/auth"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql/generated"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql/model"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	pattern2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/pattern"
 	session3 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
 )
 
-var ErrUserNotInSession = errors.New("user is not in session")
-
 // Authenticate is the resolver for the authenticate field.
 func (r *mutationResolver) Authenticate(ctx context.Context) (string, error) {
 	clientUuid, err := uuid.NewRandom()
@@ -56,12 +58,12 @@ func (r *mutationResolver) ChangeUserName(ctx context.Context, sessionGUID strin
 
 // CreateSession is the resolver for the createSession field.
 func (r *mutationResolver) CreateSession(ctx context.Context) (*model.Session, error) {
-	_, err := auth2.ForContext(ctx)
+	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
 	}
 
-	session, err := r.SessionStorage.Create()
+	session, err := r.SessionStorage.Create(clientUuid)
 	if err != nil {
 		return nil, err
 	}
@@ -119,8 +121,8 @@ func (r *mutationResolver) QuitSession(ctx context.Context, sessionGUID string)
 	return sessionGUID, nil
 }
 
-// AddWeapon is the resolver for the addWeapon field.
-func (r *mutationResolver) AddWeapon(ctx context.Context, sessionGUID string, weaponType model.WeaponType) (*model.Weapon, error) {
+// KickUser is the resolver for the kickUser field.
+func (r *mutationResolver) KickUser(ctx context.Context, sessionGUID string, clientGUID string) (*model.User, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -140,45 +142,70 @@ func (r *mutationResolver) AddWeapon(ctx context.Context, sessionGUID string, we
 		return nil, ErrUserNotInSession
 	}
 
-	weapon, err := session.AddWeapon(WeaponTypeFromGraphQL(weaponType))
+	if !session.IsHost(clientUuid) {
+		return nil, ErrUserNotHost
+	}
+
+	targetUuid, err := uuid.Parse(clientGUID)
 	if err != nil {
 		return nil, err
 	}
 
-	return WeaponToGraphQL(weapon), nil
+	if targetUuid == clientUuid {
+		return nil, errors.New("host cannot kick itself")
+	}
+
+	user, err := session.Kick(targetUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return UserToGraphQL(user), nil
 }
 
-// AddTarget is the resolver for the addTarget field.
-func (r *mutationResolver) AddTarget(ctx context.Context, sessionGUID string) (*model.Target, error) {
+// BanUser is the resolver for the banUser field.
+func (r *mutationResolver) BanUser(ctx context.Context, sessionGUID string, clientGUID string) (string, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
-		return nil, auth2.ErrNotAuthenticated
+		return "", auth2.ErrNotAuthenticated
 	}
 
 	sessionUuid, err := uuid.Parse(sessionGUID)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	session, err := r.SessionStorage.Get(sessionUuid)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	if _, err := session.User(clientUuid); err != nil {
-		return nil, ErrUserNotInSession
+		return "", ErrUserNotInSession
 	}
 
-	target, err := session.AddTarget()
+	if !session.IsHost(clientUuid) {
+		return "", ErrUserNotHost
+	}
+
+	targetUuid, err := uuid.Parse(clientGUID)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return TargetToGraphQL(target), nil
+	if targetUuid == clientUuid {
+		return "", errors.New("host cannot ban itself")
+	}
+
+	if err := session.Ban(targetUuid); err != nil {
+		return "", err
+	}
+
+	return clientGUID, nil
 }
 
-// Target is the resolver for the target field.
-func (r *mutationResolver) Target(ctx context.Context, sessionGUID string, input model.TargetInput) (*model.Target, error) {
+// TransferHost is the resolver for the transferHost field.
+func (r *mutationResolver) TransferHost(ctx context.Context, sessionGUID string, clientGUID string) (*model.User, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -198,24 +225,25 @@ func (r *mutationResolver) Target(ctx context.Context, sessionGUID string, input
 		return nil, ErrUserNotInSession
 	}
 
-	target, err := session.Target(session3.TargetId(input.ID))
-	if err != nil {
-		return nil, err
+	if !session.IsHost(clientUuid) {
+		return nil, ErrUserNotHost
 	}
 
-	if input.Active != nil {
-		target.SetActive(*input.Active)
+	targetUuid, err := uuid.Parse(clientGUID)
+	if err != nil {
+		return nil, err
 	}
 
-	if input.Position != nil {
-		target.SetPosition(Vector3InputFromGraphQL(*input.Position))
+	user, err := session.TransferHost(targetUuid)
+	if err != nil {
+		return nil, err
 	}
 
-	return TargetToGraphQL(target), nil
+	return UserToGraphQL(user), nil
 }
 
-// Weapon is the resolver for the weapon field.
-func (r *mutationResolver) Weapon(ctx context.Context, sessionGUID string, input model.WeaponInput) (*model.Weapon, error) {
+// SetUserRole is the resolver for the setUserRole field.
+func (r *mutationResolver) SetUserRole(ctx context.Context, sessionGUID string, clientGUID string, role model.Role) (*model.User, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -231,28 +259,68 @@ func (r *mutationResolver) Weapon(ctx context.Context, sessionGUID string, input
 		return nil, err
 	}
 
-	if _, err := session.User(clientUuid); err != nil {
+	user, err := session.User(clientUuid)
+	if err != nil {
 		return nil, ErrUserNotInSession
 	}
 
-	weapon, err := session.Weapon(session3.WeaponId(input.ID))
+	if !session.IsHost(clientUuid) {
+		if err := authorize(user, session3.ManageRolesPermission); err != nil {
+			return nil, err
+		}
+	}
+
+	targetUuid, err := uuid.Parse(clientGUID)
 	if err != nil {
 		return nil, err
 	}
 
-	if input.Active != nil {
-		weapon.SetActive(*input.Active)
+	if session.IsHost(targetUuid) {
+		return nil, ErrRoleOfHost
 	}
 
-	if input.Position != nil {
-		weapon.SetPosition(Vector3InputFromGraphQL(*input.Position))
+	targetUser, err := session.User(targetUuid)
+	if err != nil {
+		return nil, err
 	}
 
-	return WeaponToGraphQL(weapon), nil
+	targetUser.SetRole(RoleFromGraphQL(role))
+
+	return UserToGraphQL(targetUser), nil
 }
 
-// AcquireTarget is the resolver for the acquireTarget field.
-func (r *mutationResolver) AcquireTarget(ctx context.Context, sessionGUID string, id int) (*model.Target, error) {
+// SetDefaultRole is the resolver for the setDefaultRole field.
+func (r *mutationResolver) SetDefaultRole(ctx context.Context, sessionGUID string, role model.Role) (model.Role, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return "", auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return "", err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return "", ErrUserNotInSession
+	}
+
+	if !session.IsHost(clientUuid) {
+		return "", ErrUserNotHost
+	}
+
+	session.SetDefaultRole(RoleFromGraphQL(role))
+
+	return role, nil
+}
+
+// SetSessionMap is the resolver for the setSessionMap field.
+func (r *mutationResolver) SetSessionMap(ctx context.Context, sessionGUID string, mapID string) (*model.Map, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -273,49 +341,57 @@ func (r *mutationResolver) AcquireTarget(ctx context.Context, sessionGUID string
 		return nil, ErrUserNotInSession
 	}
 
-	target, err := session.Target(session3.TargetId(id))
+	if !session.IsHost(clientUuid) {
+		if err := authorize(user, session3.ManageSessionPermission); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := r.MapCatalog.Map(mapID)
 	if err != nil {
 		return nil, err
 	}
 
-	target.SetOwner(user)
+	session.SetMap(m, r.heightmap(m.Id))
 
-	return TargetToGraphQL(target), nil
+	return MapToGraphQL(&m), nil
 }
 
-// ReleaseTarget is the resolver for the releaseTarget field.
-func (r *mutationResolver) ReleaseTarget(ctx context.Context, sessionGUID string, id int) (*model.Target, error) {
+// SetBlockDangerCloseFire is the resolver for the setBlockDangerCloseFire field.
+func (r *mutationResolver) SetBlockDangerCloseFire(ctx context.Context, sessionGUID string, block bool) (bool, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
-		return nil, auth2.ErrNotAuthenticated
+		return false, auth2.ErrNotAuthenticated
 	}
 
 	sessionUuid, err := uuid.Parse(sessionGUID)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
 	session, err := r.SessionStorage.Get(sessionUuid)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	if _, err := session.User(clientUuid); err != nil {
-		return nil, ErrUserNotInSession
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return false, ErrUserNotInSession
 	}
 
-	target, err := session.Target(session3.TargetId(id))
-	if err != nil {
-		return nil, err
+	if !session.IsHost(clientUuid) {
+		if err := authorize(user, session3.ManageSessionPermission); err != nil {
+			return false, err
+		}
 	}
 
-	target.SetOwner(nil)
+	session.SetBlockDangerCloseFire(block)
 
-	return TargetToGraphQL(target), nil
+	return block, nil
 }
 
-// AcquireWeapon is the resolver for the acquireWeapon field.
-func (r *mutationResolver) AcquireWeapon(ctx context.Context, sessionGUID string, id int) (*model.Weapon, error) {
+// ReportPosition is the resolver for the reportPosition field.
+func (r *mutationResolver) ReportPosition(ctx context.Context, sessionGUID string, position *model.Vector3Input, gridRef *string, ttl *float64) (*model.User, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -336,18 +412,24 @@ func (r *mutationResolver) AcquireWeapon(ctx context.Context, sessionGUID string
 		return nil, ErrUserNotInSession
 	}
 
-	weapon, err := session.Weapon(session3.WeaponId(id))
+	if position == nil && gridRef == nil {
+		return nil, ErrMissingPosition
+	}
+
+	v, err := r.inputPosition(session, position, gridRef, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	weapon.SetOwner(user)
+	if err := session.ReportPosition(user, v, DurationFromGraphQL(ttl)); err != nil {
+		return nil, err
+	}
 
-	return WeaponToGraphQL(weapon), nil
+	return UserToGraphQL(user), nil
 }
 
-// ReleaseWeapon is the resolver for the releaseWeapon field.
-func (r *mutationResolver) ReleaseWeapon(ctx context.Context, sessionGUID string, id int) (*model.Weapon, error) {
+// AddWeapon is the resolver for the addWeapon field.
+func (r *mutationResolver) AddWeapon(ctx context.Context, sessionGUID string, weaponType string) (*model.Weapon, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -363,22 +445,30 @@ func (r *mutationResolver) ReleaseWeapon(ctx context.Context, sessionGUID string
 		return nil, err
 	}
 
-	if _, err := session.User(clientUuid); err != nil {
+	user, err := session.User(clientUuid)
+	if err != nil {
 		return nil, ErrUserNotInSession
 	}
 
-	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err := authorize(user, session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	t, err := r.WeaponRegistry.WeaponType(weaponType)
 	if err != nil {
 		return nil, err
 	}
 
-	weapon.SetOwner(nil)
+	weapon, err := session.AddWeapon(t)
+	if err != nil {
+		return nil, err
+	}
 
 	return WeaponToGraphQL(weapon), nil
 }
 
-// Users is the resolver for the users field.
-func (r *queryResolver) Users(ctx context.Context, sessionGUID string) ([]*model.User, error) {
+// AddTarget is the resolver for the addTarget field.
+func (r *mutationResolver) AddTarget(ctx context.Context, sessionGUID string, polar *model.PolarInput) (*model.Target, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -394,15 +484,41 @@ func (r *queryResolver) Users(ctx context.Context, sessionGUID string) ([]*model
 		return nil, err
 	}
 
-	if _, err := session.User(clientUuid); err != nil {
+	user, err := session.User(clientUuid)
+	if err != nil {
 		return nil, ErrUserNotInSession
 	}
 
-	return slice.Map(session.Users(), UserToGraphQL), nil
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	var position math.Vector3
+	if polar != nil {
+		position, err = r.polarPosition(session, *polar)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validatePosition(session, position); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := session.AddTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	if polar != nil {
+		target.SetPosition(position)
+	}
+
+	return TargetToGraphQL(target), nil
 }
 
-// Targets is the resolver for the targets field.
-func (r *queryResolver) Targets(ctx context.Context, sessionGUID string) ([]*model.Target, error) {
+// Target is the resolver for the target field.
+func (r *mutationResolver) Target(ctx context.Context, sessionGUID string, input model.TargetInput) (*model.Target, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -418,15 +534,38 @@ func (r *queryResolver) Targets(ctx context.Context, sessionGUID string) ([]*mod
 		return nil, err
 	}
 
-	if _, err := session.User(clientUuid); err != nil {
+	user, err := session.User(clientUuid)
+	if err != nil {
 		return nil, ErrUserNotInSession
 	}
 
-	return slice.Map(session.Targets(), TargetToGraphQL), nil
+	target, err := session.Target(session3.TargetId(input.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwned(user, target.Owner(), session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	if input.Position != nil || input.GridRef != nil || input.Polar != nil {
+		position, err := r.inputPosition(session, input.Position, input.GridRef, input.Polar)
+		if err != nil {
+			return nil, err
+		}
+
+		target.SetPosition(position)
+	}
+
+	if input.Active != nil {
+		target.SetActive(*input.Active)
+	}
+
+	return TargetToGraphQL(target), nil
 }
 
-// Weapons is the resolver for the weapons field.
-func (r *queryResolver) Weapons(ctx context.Context, sessionGUID string) ([]*model.Weapon, error) {
+// Weapon is the resolver for the weapon field.
+func (r *mutationResolver) Weapon(ctx context.Context, sessionGUID string, input model.WeaponInput) (*model.Weapon, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -442,15 +581,80 @@ func (r *queryResolver) Weapons(ctx context.Context, sessionGUID string) ([]*mod
 		return nil, err
 	}
 
-	if _, err := session.User(clientUuid); err != nil {
+	user, err := session.User(clientUuid)
+	if err != nil {
 		return nil, ErrUserNotInSession
 	}
 
-	return slice.Map(session.Weapons(), WeaponToGraphQL), nil
+	weapon, err := session.Weapon(session3.WeaponId(input.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwned(user, weapon.Owner(), session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	// All inputs are validated before the first change is applied, so that an invalid input does
+	// not leave the weapon partially updated.
+	var position *math.Vector3
+	if input.Position != nil || input.GridRef != nil {
+		v, err := r.inputPosition(session, input.Position, input.GridRef, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		position = &v
+	}
+
+	if input.Ammunition != nil {
+		if _, err := weapon.Type().FindAmmunition(*input.Ammunition); err != nil {
+			return nil, err
+		}
+	}
+
+	sector := weapon.Sector()
+	if input.Heading != nil || input.TraverseArc != nil {
+		milsPerCircle := weapon.Type().MilsPerCircle
+
+		if input.Heading != nil {
+			sector.Heading = AngleFromGraphQL(*input.Heading, input.Unit, model.AngleUnitDegrees, milsPerCircle)
+		}
+
+		if input.TraverseArc != nil {
+			sector.Arc = AngleFromGraphQL(*input.TraverseArc, input.Unit, model.AngleUnitDegrees, milsPerCircle)
+		}
+
+		if _, err := weapon.Type().Sector(sector.Heading, sector.Arc); err != nil {
+			return nil, err
+		}
+	}
+
+	if position != nil {
+		weapon.SetPosition(*position)
+	}
+
+	if input.Active != nil {
+		weapon.SetActive(*input.Active)
+	}
+
+	if input.Ammunition != nil {
+		if err := weapon.SetAmmunition(*input.Ammunition); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Heading != nil || input.TraverseArc != nil {
+		if err := weapon.SetSector(sector.Heading, sector.Arc); err != nil {
+			return nil, err
+		}
+	}
+
+	return WeaponToGraphQL(weapon), nil
 }
 
-// SessionUpdates is the resolver for the sessionUpdates field.
-func (r *subscriptionResolver) SessionUpdates(ctx context.Context, sessionGUID string) (<-chan *model.SessionUpdate, error) {
+// AdjustTarget is the resolver for the adjustTarget field.
+func (r *mutationResolver) AdjustTarget(ctx context.Context, sessionGUID string, id int, weaponID int, addDrop *float64, leftRight *float64) (*model.Target, error) {
 	clientUuid, err := auth2.ForContext(ctx)
 	if err != nil {
 		return nil, auth2.ErrNotAuthenticated
@@ -461,28 +665,1337 @@ func (r *subscriptionResolver) SessionUpdates(ctx context.Context, sessionGUID s
 		return nil, err
 	}
 
-	s, err := r.SessionStorage.Get(sessionUuid)
+	session, err := r.SessionStorage.Get(sessionUuid)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := s.User(clientUuid); err != nil {
+	user, err := session.User(clientUuid)
+	if err != nil {
 		return nil, ErrUserNotInSession
 	}
 
-	ch := make(chan *model.SessionUpdate, 4)
+	target, err := session.Target(session3.TargetId(id))
+	if err != nil {
+		return nil, err
+	}
 
-	go func() {
-		sub := s.Subscribe()
+	if err := authorizeOwned(user, target.Owner(), session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
 
-		for {
-			select {
-			case <-ctx.Done():
-				sub.Unsubscribe()
-				close(ch)
-				return
-			case change := <-sub.Chan():
-				ch <- SessionChangeToGraphQL(&change)
+	weapon, err := session.Weapon(session3.WeaponId(weaponID))
+	if err != nil {
+		return nil, err
+	}
+
+	var along, across float64
+	if addDrop != nil {
+		along = *addDrop
+	}
+	if leftRight != nil {
+		across = *leftRight
+	}
+
+	position := target.Position()
+
+	offset, ok := math.LineOffset(weapon.Position(), position, float32(along), float32(across))
+	if !ok {
+		return nil, ErrNoGunTargetLine
+	}
+
+	if heightmap := r.sessionHeightmap(session); heightmap != nil {
+		if height, ok := heightmap.Height(position.Add(offset)); ok {
+			offset.Z = height - position.Z
+		}
+	}
+
+	if err := validatePosition(session, position.Add(offset)); err != nil {
+		return nil, err
+	}
+
+	target.AddPosition(offset)
+
+	return TargetToGraphQL(target), nil
+}
+
+// RemoveWeapon is the resolver for the removeWeapon field.
+func (r *mutationResolver) RemoveWeapon(ctx context.Context, sessionGUID string, id int) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwned(user, weapon.Owner(), session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	weapon, err = session.RemoveWeapon(weapon.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// RemoveTarget is the resolver for the removeTarget field.
+func (r *mutationResolver) RemoveTarget(ctx context.Context, sessionGUID string, id int) (*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	target, err := session.Target(session3.TargetId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeRemoveTarget(user, target, session.Targets()); err != nil {
+		return nil, err
+	}
+
+	target, err = session.RemoveTarget(target.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	return TargetToGraphQL(target), nil
+}
+
+// ClearTargets is the resolver for the clearTargets field.
+func (r *mutationResolver) ClearTargets(ctx context.Context, sessionGUID string) ([]*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	all := session.Targets()
+
+	children := make(map[session3.TargetId][]session3.Target)
+	for _, target := range all {
+		if parentId, ok := target.ParentId(); ok {
+			children[parentId] = append(children[parentId], target)
+		}
+	}
+
+	// Children are removed with their parent, so they are skipped afterwards.
+	removedIds := make(map[session3.TargetId]struct{})
+
+	var targets []*model.Target
+	for _, target := range all {
+		if _, ok := removedIds[target.Id()]; ok {
+			continue
+		}
+
+		if authorizeRemoveTarget(user, target, all) != nil {
+			continue
+		}
+
+		removed, err := session.RemoveTarget(target.Id())
+		if err != nil {
+			return nil, err
+		}
+
+		removedIds[removed.Id()] = struct{}{}
+		targets = append(targets, TargetToGraphQL(removed))
+
+		for _, child := range children[removed.Id()] {
+			if _, ok := removedIds[child.Id()]; ok {
+				continue
+			}
+
+			removedIds[child.Id()] = struct{}{}
+			targets = append(targets, TargetToGraphQL(child))
+		}
+	}
+
+	return targets, nil
+}
+
+// AcquireTarget is the resolver for the acquireTarget field.
+func (r *mutationResolver) AcquireTarget(ctx context.Context, sessionGUID string, id int, lease *float64) (*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	target, err := session.Target(session3.TargetId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	if err := target.Acquire(user, DurationFromGraphQL(lease)); err != nil {
+		return nil, err
+	}
+
+	return TargetToGraphQL(target), nil
+}
+
+// ReleaseTarget is the resolver for the releaseTarget field.
+func (r *mutationResolver) ReleaseTarget(ctx context.Context, sessionGUID string, id int) (*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	target, err := session.Target(session3.TargetId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	owner := target.Owner()
+	if err := authorizeOwned(user, owner, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	// Only users with the override permission may release it on behalf of another owner.
+	if owner != nil && owner.ClientUuid() != user.ClientUuid() {
+		target.SetOwner(nil)
+	} else if err := target.Release(user); err != nil {
+		return nil, err
+	}
+
+	return TargetToGraphQL(target), nil
+}
+
+// RequestTargetHandover is the resolver for the requestTargetHandover field.
+func (r *mutationResolver) RequestTargetHandover(ctx context.Context, sessionGUID string, id int) (*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	target, err := session.Target(session3.TargetId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.RequestHandover(user); err != nil {
+		return nil, err
+	}
+
+	return TargetToGraphQL(target), nil
+}
+
+// RespondTargetHandover is the resolver for the respondTargetHandover field.
+func (r *mutationResolver) RespondTargetHandover(ctx context.Context, sessionGUID string, id int, accept bool) (*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	target, err := session.Target(session3.TargetId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.RespondHandover(user, accept); err != nil {
+		return nil, err
+	}
+
+	return TargetToGraphQL(target), nil
+}
+
+// AcquireWeapon is the resolver for the acquireWeapon field.
+func (r *mutationResolver) AcquireWeapon(ctx context.Context, sessionGUID string, id int, lease *float64) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorize(user, session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	if err := weapon.Acquire(user, DurationFromGraphQL(lease)); err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// ReleaseWeapon is the resolver for the releaseWeapon field.
+func (r *mutationResolver) ReleaseWeapon(ctx context.Context, sessionGUID string, id int) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	owner := weapon.Owner()
+	if err := authorizeOwned(user, owner, session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	// Only users with the override permission may release it on behalf of another owner.
+	if owner != nil && owner.ClientUuid() != user.ClientUuid() {
+		weapon.SetOwner(nil)
+	} else if err := weapon.Release(user); err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// RequestWeaponHandover is the resolver for the requestWeaponHandover field.
+func (r *mutationResolver) RequestWeaponHandover(ctx context.Context, sessionGUID string, id int) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := weapon.RequestHandover(user); err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// RespondWeaponHandover is the resolver for the respondWeaponHandover field.
+func (r *mutationResolver) RespondWeaponHandover(ctx context.Context, sessionGUID string, id int, accept bool) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := weapon.RespondHandover(user, accept); err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// RequestFireMission is the resolver for the requestFireMission field.
+func (r *mutationResolver) RequestFireMission(ctx context.Context, sessionGUID string, targetIds []int, weaponIds []int, rounds int) (*model.FireMission, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	fireMission, err := session.RequestFireMission(
+		user,
+		slice.Map(targetIds, func(id int) session3.TargetId {
+			return session3.TargetId(id)
+		}),
+		slice.Map(weaponIds, func(id int) session3.WeaponId {
+			return session3.WeaponId(id)
+		}),
+		rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	return FireMissionToGraphQL(fireMission), nil
+}
+
+// SetFireMissionState is the resolver for the setFireMissionState field.
+func (r *mutationResolver) SetFireMissionState(ctx context.Context, sessionGUID string, id int, state model.FireMissionState) (*model.FireMission, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	fireMissionState := FireMissionStateFromGraphQL(state)
+	if err := authorizeFireMissionState(user, fireMissionState); err != nil {
+		return nil, err
+	}
+
+	fireMission, err := session.FireMission(session3.FireMissionId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fireMission.SetState(user, fireMissionState); err != nil {
+		return nil, err
+	}
+
+	return FireMissionToGraphQL(fireMission), nil
+}
+
+// RemoveFireMission is the resolver for the removeFireMission field.
+func (r *mutationResolver) RemoveFireMission(ctx context.Context, sessionGUID string, id int) (*model.FireMission, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	fireMission, err := session.RemoveFireMission(session3.FireMissionId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return FireMissionToGraphQL(fireMission), nil
+}
+
+// ScheduleTimeOnTarget is the resolver for the scheduleTimeOnTarget field.
+func (r *mutationResolver) ScheduleTimeOnTarget(ctx context.Context, sessionGUID string, targetID int, weaponIds []int, delay *float64) (*model.TimeOnTarget, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	timeOnTarget, err := session.ScheduleTimeOnTarget(
+		user,
+		session3.TargetId(targetID),
+		slice.Map(weaponIds, func(id int) session3.WeaponId {
+			return session3.WeaponId(id)
+		}),
+		DurationFromGraphQL(delay))
+	if err != nil {
+		return nil, err
+	}
+
+	return TimeOnTargetToGraphQL(timeOnTarget), nil
+}
+
+// CancelTimeOnTarget is the resolver for the cancelTimeOnTarget field.
+func (r *mutationResolver) CancelTimeOnTarget(ctx context.Context, sessionGUID string, id int) (*model.TimeOnTarget, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	timeOnTarget, err := session.CancelTimeOnTarget(session3.TimeOnTargetId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return TimeOnTargetToGraphQL(timeOnTarget), nil
+}
+
+// Fire is the resolver for the fire field.
+func (r *mutationResolver) Fire(ctx context.Context, sessionGUID string, weaponID int, targetID *int) (*model.Shot, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(weaponID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwned(user, weapon.Owner(), session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	var id *session3.TargetId
+	if targetID != nil {
+		v := session3.TargetId(*targetID)
+		id = &v
+	}
+
+	shot, err := session.Fire(user, weapon.Id(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ShotToGraphQL(&shot), nil
+}
+
+// AddPatternTargets is the resolver for the addPatternTargets field.
+func (r *mutationResolver) AddPatternTargets(ctx context.Context, sessionGUID string, targetID int, pattern model.FirePatternInput) ([]*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if err := authorize(user, session3.ManageTargetsPermission); err != nil {
+		return nil, err
+	}
+
+	target, err := session.Target(session3.TargetId(targetID))
+	if err != nil {
+		return nil, err
+	}
+
+	aimPoints, err := r.aimPoints(session, target, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := session.AddChildTargets(target.Id(), slice.Map(aimPoints, func(aimPoint pattern2.AimPoint) math.Vector3 {
+		return aimPoint.Position
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return slice.Map(targets, TargetToGraphQL), nil
+}
+
+// PlanAssignments is the resolver for the planAssignments field.
+func (r *mutationResolver) PlanAssignments(ctx context.Context, sessionGUID string, layTime *float64) (*model.AssignmentPlan, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	if !session.IsHost(clientUuid) {
+		if err := authorize(user, session3.ManageSessionPermission); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := session.PlanAssignments(DurationFromGraphQL(layTime))
+	if err != nil {
+		return nil, err
+	}
+
+	return PlanToGraphQL(&plan), nil
+}
+
+// SetPredictedImpact is the resolver for the setPredictedImpact field.
+func (r *mutationResolver) SetPredictedImpact(ctx context.Context, sessionGUID string, id int, elevation float64, azimuth float64, unit *model.AngleUnit) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwned(user, weapon.Owner(), session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	profile := weapon.Type().Profile()
+
+	weapon, err = session.PredictImpact(
+		weapon.Id(),
+		AngleFromGraphQL(elevation, unit, model.AngleUnitMils, profile.MilsPerCircle),
+		AngleFromGraphQL(azimuth, unit, model.AngleUnitMils, profile.MilsPerCircle))
+	if err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// ClearPredictedImpact is the resolver for the clearPredictedImpact field.
+func (r *mutationResolver) ClearPredictedImpact(ctx context.Context, sessionGUID string, id int) (*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := session.User(clientUuid)
+	if err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	weapon, err := session.Weapon(session3.WeaponId(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwned(user, weapon.Owner(), session3.ManageWeaponsPermission); err != nil {
+		return nil, err
+	}
+
+	weapon, err = session.ClearPredictedImpact(weapon.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	return WeaponToGraphQL(weapon), nil
+}
+
+// ServerTime is the resolver for the serverTime field.
+func (r *queryResolver) ServerTime(ctx context.Context) (*time.Time, error) {
+	now := time.Now()
+
+	return &now, nil
+}
+
+// Session is the resolver for the session field.
+func (r *queryResolver) Session(ctx context.Context, sessionGUID string) (*model.Session, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return SessionToGraphQL(session), nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, sessionGUID string) ([]*model.User, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return slice.Map(session.Users(), UserToGraphQL), nil
+}
+
+// Targets is the resolver for the targets field.
+func (r *queryResolver) Targets(ctx context.Context, sessionGUID string) ([]*model.Target, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return slice.Map(session.Targets(), TargetToGraphQL), nil
+}
+
+// Weapons is the resolver for the weapons field.
+func (r *queryResolver) Weapons(ctx context.Context, sessionGUID string) ([]*model.Weapon, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return slice.Map(session.Weapons(), WeaponToGraphQL), nil
+}
+
+// FireMissions is the resolver for the fireMissions field.
+func (r *queryResolver) FireMissions(ctx context.Context, sessionGUID string) ([]*model.FireMission, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return slice.Map(session.FireMissions(), FireMissionToGraphQL), nil
+}
+
+// TimeOnTargets is the resolver for the timeOnTargets field.
+func (r *queryResolver) TimeOnTargets(ctx context.Context, sessionGUID string) ([]*model.TimeOnTarget, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return slice.Map(session.TimeOnTargets(), TimeOnTargetToGraphQL), nil
+}
+
+// AssignmentPlan is the resolver for the assignmentPlan field.
+func (r *queryResolver) AssignmentPlan(ctx context.Context, sessionGUID string) (*model.AssignmentPlan, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	plan, ok := session.Plan()
+	if !ok {
+		return nil, nil
+	}
+
+	return PlanToGraphQL(&plan), nil
+}
+
+// Effects is the resolver for the effects field.
+func (r *queryResolver) Effects(ctx context.Context, sessionGUID string) ([]*model.Effect, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return EffectsToGraphQL(session.Effects()), nil
+}
+
+// DangerCloseWarnings is the resolver for the dangerCloseWarnings field.
+func (r *queryResolver) DangerCloseWarnings(ctx context.Context, sessionGUID string) ([]*model.DangerCloseWarning, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	return DangerCloseWarningsToGraphQL(session.DangerCloseWarnings()), nil
+}
+
+// Maps is the resolver for the maps field.
+func (r *queryResolver) Maps(ctx context.Context) ([]*model.Map, error) {
+	if _, err := auth2.ForContext(ctx); err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	maps := r.MapCatalog.Maps()
+
+	gameMaps := make([]*model.Map, len(maps))
+	for i := range maps {
+		gameMaps[i] = MapToGraphQL(&maps[i])
+	}
+
+	return gameMaps, nil
+}
+
+// WeaponTypes is the resolver for the weaponTypes field.
+func (r *queryResolver) WeaponTypes(ctx context.Context) ([]*model.WeaponType, error) {
+	if _, err := auth2.ForContext(ctx); err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	return slice.Map(r.WeaponRegistry.WeaponTypes(), WeaponTypeToGraphQL), nil
+}
+
+// GridRef is the resolver for the gridRef field.
+func (r *queryResolver) GridRef(ctx context.Context, mapID string, position model.Vector3Input, keypads *int) (string, error) {
+	if _, err := auth2.ForContext(ctx); err != nil {
+		return "", auth2.ErrNotAuthenticated
+	}
+
+	m, err := r.MapCatalog.Map(mapID)
+	if err != nil {
+		return "", err
+	}
+
+	depth := 3
+	if keypads != nil {
+		depth = *keypads
+	}
+
+	return m.Grid().Format(Vector3InputFromGraphQL(position, nil), depth)
+}
+
+// GridPosition is the resolver for the gridPosition field.
+func (r *queryResolver) GridPosition(ctx context.Context, mapID string, gridRef string) (*math.Vector3, error) {
+	if _, err := auth2.ForContext(ctx); err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	m, err := r.MapCatalog.Map(mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := GridRefFromGraphQL(gridRef, m, r.heightmap(m.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// FiringSolution is the resolver for the firingSolution field.
+func (r *queryResolver) FiringSolution(ctx context.Context, weaponType string, from model.Vector3Input, to model.Vector3Input, mapID *string, ammunition *string) (*model.FiringSolution, error) {
+	if _, err := auth2.ForContext(ctx); err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	var heightmap terrain.Heightmap
+	if mapID != nil {
+		heightmap = r.heightmap(*mapID)
+	}
+
+	fromPosition := Vector3InputFromGraphQL(from, heightmap)
+
+	profile, err := r.profile(weaponType, ammunition)
+	if err != nil {
+		return nil, err
+	}
+
+	solution := profile.Solve(fromPosition, Vector3InputFromGraphQL(to, heightmap))
+
+	var clearance *terrain.Clearance
+	if c, ok := terrain.CheckClearance(heightmap, fromPosition, solution); ok {
+		clearance = &c
+	}
+
+	return FiringSolutionToGraphQL(solution, clearance), nil
+}
+
+// FirePattern is the resolver for the firePattern field.
+func (r *queryResolver) FirePattern(ctx context.Context, sessionGUID string, targetID int, pattern model.FirePatternInput) ([]*model.AimPoint, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := session.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	target, err := session.Target(session3.TargetId(targetID))
+	if err != nil {
+		return nil, err
+	}
+
+	aimPoints, err := r.aimPoints(session, target, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return slice.Map(aimPoints, AimPointToGraphQL), nil
+}
+
+// PredictImpact is the resolver for the predictImpact field.
+func (r *queryResolver) PredictImpact(ctx context.Context, weaponType string, from model.Vector3Input, elevation float64, azimuth float64, unit *model.AngleUnit, mapID *string, ammunition *string) (*model.ImpactPrediction, error) {
+	if _, err := auth2.ForContext(ctx); err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	var heightmap terrain.Heightmap
+	if mapID != nil {
+		heightmap = r.heightmap(*mapID)
+	}
+
+	profile, err := r.profile(weaponType, ammunition)
+	if err != nil {
+		return nil, err
+	}
+
+	solution, err := profile.Aim(AngleFromGraphQL(elevation, unit, model.AngleUnitMils, profile.MilsPerCircle), AngleFromGraphQL(azimuth, unit, model.AngleUnitMils, profile.MilsPerCircle))
+	if err != nil {
+		return nil, err
+	}
+
+	prediction := terrain.PredictImpact(heightmap, Vector3InputFromGraphQL(from, heightmap), solution)
+
+	return ImpactPredictionToGraphQL(&prediction), nil
+}
+
+// SessionUpdates is the resolver for the sessionUpdates field.
+func (r *subscriptionResolver) SessionUpdates(ctx context.Context, sessionGUID string) (<-chan *model.SessionUpdate, error) {
+	clientUuid, err := auth2.ForContext(ctx)
+	if err != nil {
+		return nil, auth2.ErrNotAuthenticated
+	}
+
+	sessionUuid, err := uuid.Parse(sessionGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := r.SessionStorage.Get(sessionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.User(clientUuid); err != nil {
+		return nil, ErrUserNotInSession
+	}
+
+	ch := make(chan *model.SessionUpdate, 4)
+
+	go func() {
+		sub := s.Subscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				close(ch)
+				return
+			case change := <-sub.Chan():
+				ch <- SessionChangeToGraphQL(&change)
+
+				if change.SessionClosed != nil || (change.UserKicked != nil && change.UserKicked.ClientUuid() == clientUuid) {
+					sub.Unsubscribe()
+					close(ch)
+					return
+				}
 			}
 		}
 	}()
@@ -0,0 +1,27 @@
+package graphql
+
+import (
+	session2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
+)
+
+// heightmap returns the heightmap of the map or nil if the server has none for it.
+func (r *Resolver) heightmap(mapId string) terrain.Heightmap {
+	heightmap, err := r.Heightmaps.Heightmap(mapId)
+	if err != nil {
+		return nil
+	}
+
+	return heightmap
+}
+
+// sessionHeightmap returns the heightmap of the map of the session or nil if it has no map or the
+// server has no heightmap for it.
+func (r *Resolver) sessionHeightmap(session session2.Session) terrain.Heightmap {
+	m, ok := session.Map()
+	if !ok {
+		return nil
+	}
+
+	return r.heightmap(m.Id)
+}
@@ -0,0 +1,127 @@
+package graphql
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/graphql/model"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	session2 "github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
+	stdmath "math"
+)
+
+// validatePosition checks that the position lies within the map of the session if one has been selected.
+func validatePosition(session session2.Session, position math.Vector3) error {
+	m, ok := session.Map()
+	if !ok || m.Contains(position) {
+		return nil
+	}
+
+	return ErrPositionOutOfBounds
+}
+
+// inputPosition resolves a position given either as coordinates, as a grid reference on the map of
+// the session or relative to a reference point and validates it against the map bounds.
+func (r *Resolver) inputPosition(session session2.Session, position *model.Vector3Input, gridRef *string, polar *model.PolarInput) (math.Vector3, error) {
+	if count(position != nil, gridRef != nil, polar != nil) > 1 {
+		return math.Vector3{}, ErrAmbiguousPosition
+	}
+
+	var v math.Vector3
+	var err error
+
+	switch {
+	case gridRef != nil:
+		v, err = r.gridRefPosition(session, *gridRef)
+	case polar != nil:
+		v, err = r.polarPosition(session, *polar)
+	default:
+		v = Vector3InputFromGraphQL(*position, r.sessionHeightmap(session))
+	}
+
+	if err != nil {
+		return math.Vector3{}, err
+	}
+
+	if err := validatePosition(session, v); err != nil {
+		return math.Vector3{}, err
+	}
+
+	return v, nil
+}
+
+func (r *Resolver) gridRefPosition(session session2.Session, gridRef string) (math.Vector3, error) {
+	m, ok := session.Map()
+	if !ok {
+		return math.Vector3{}, ErrSessionHasNoMap
+	}
+
+	return GridRefFromGraphQL(gridRef, m, r.sessionHeightmap(session))
+}
+
+// polarPosition resolves a position given by bearing and distance from a reference point.
+func (r *Resolver) polarPosition(session session2.Session, polar model.PolarInput) (math.Vector3, error) {
+	if count(polar.From != nil, polar.FromGridRef != nil, polar.FromTargetID != nil, polar.FromWeaponID != nil) != 1 {
+		return math.Vector3{}, ErrAmbiguousReference
+	}
+
+	heightmap := r.sessionHeightmap(session)
+
+	var reference math.Vector3
+	switch {
+	case polar.From != nil:
+		reference = Vector3InputFromGraphQL(*polar.From, heightmap)
+	case polar.FromGridRef != nil:
+		v, err := r.gridRefPosition(session, *polar.FromGridRef)
+		if err != nil {
+			return math.Vector3{}, err
+		}
+
+		reference = v
+	case polar.FromTargetID != nil:
+		target, err := session.Target(session2.TargetId(*polar.FromTargetID))
+		if err != nil {
+			return math.Vector3{}, err
+		}
+
+		reference = target.Position()
+	case polar.FromWeaponID != nil:
+		weapon, err := session.Weapon(session2.WeaponId(*polar.FromWeaponID))
+		if err != nil {
+			return math.Vector3{}, err
+		}
+
+		reference = weapon.Position()
+	}
+
+	bearing := AngleFromGraphQL(polar.Bearing, polar.Unit, model.AngleUnitDegrees, milsPerCircle)
+
+	if polar.Elevation != nil {
+		elevation := AngleFromGraphQL(*polar.Elevation, polar.Unit, model.AngleUnitDegrees, milsPerCircle)
+
+		v := reference.Polar(bearing, polar.Distance*stdmath.Cos(elevation))
+		v.Z += float32(polar.Distance * stdmath.Sin(elevation))
+
+		return v, nil
+	}
+
+	v := reference.Polar(bearing, polar.Distance)
+	if heightmap != nil {
+		if height, ok := heightmap.Height(v); ok {
+			v.Z = height
+		}
+	}
+
+	return v, nil
+}
+
+// milsPerCircle is used for angles in mils which are not related to a weapon.
+const milsPerCircle = 6400
+
+func count(conditions ...bool) int {
+	n := 0
+	for _, condition := range conditions {
+		if condition {
+			n++
+		}
+	}
+
+	return n
+}
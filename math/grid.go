@@ -0,0 +1,135 @@
+package math
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidGridRef = errors.New("invalid grid reference")
+var ErrOutsideOfGrid = errors.New("position is outside of the grid")
+var ErrInvalidKeypads = errors.New("number of keypads must be between 0 and 5")
+
+// MaxKeypads is the largest number of keypads of a formatted grid reference. A fifth keypad is
+// already smaller than a meter on every map.
+const MaxKeypads = 5
+
+// gridColumns is the number of grid columns which can be named by a single letter.
+const gridColumns = 26
+
+// maxRowDigits is the number of digits of the largest row number.
+const maxRowDigits = 2
+
+// Grid converts between positions and Squad keypad grid references like C4-7-3-1. Columns are
+// lettered from west to east and rows numbered from north to south, starting at the origin. Every
+// keypad divides a cell into 3x3 cells numbered like a numeric keypad with 7 in the north-west.
+type Grid struct {
+	Origin Vector3
+	Size   float32
+}
+
+// Parse returns the center of the smallest cell referenced by the grid reference. The row has to be
+// separated from the keypads by a dash or space, as a row may have more than one digit. The keypads
+// may be separated by dashes or spaces or be written without separators, like C4-731. The height
+// is always 0.
+func (g Grid) Parse(ref string) (Vector3, error) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if ref == "" || ref[0] < 'A' || ref[0] > 'Z' {
+		return Vector3{}, ErrInvalidGridRef
+	}
+
+	column := int(ref[0] - 'A')
+
+	rest := ref[1:]
+	end := strings.IndexAny(rest, "- ")
+	if end < 0 {
+		end = len(rest)
+	}
+
+	if end > maxRowDigits {
+		return Vector3{}, ErrInvalidGridRef
+	}
+
+	row, err := strconv.Atoi(rest[:end])
+	if err != nil || row < 1 {
+		return Vector3{}, ErrInvalidGridRef
+	}
+
+	cell := float64(g.Size)
+	x := float64(column) * cell
+	y := float64(row-1) * cell
+
+	for _, r := range rest[end:] {
+		if r == '-' || r == ' ' {
+			continue
+		}
+
+		if r < '1' || r > '9' {
+			return Vector3{}, ErrInvalidGridRef
+		}
+
+		keypad := int(r - '1')
+
+		cell /= 3
+		x += float64(keypad%3) * cell
+		y += float64(2-keypad/3) * cell
+	}
+
+	return Vector3{
+		X: g.Origin.X + float32(x+cell/2),
+		Y: g.Origin.Y + float32(y+cell/2),
+	}, nil
+}
+
+// Format returns the grid reference of the position with the given number of keypads, at most
+// MaxKeypads.
+func (g Grid) Format(v Vector3, keypads int) (string, error) {
+	if keypads < 0 || keypads > MaxKeypads {
+		return "", ErrInvalidKeypads
+	}
+
+	x := float64(v.X - g.Origin.X)
+	y := float64(v.Y - g.Origin.Y)
+
+	cell := float64(g.Size)
+	column := int(math.Floor(x / cell))
+	row := int(math.Floor(y / cell))
+
+	if x < 0 || y < 0 || column >= gridColumns {
+		return "", ErrOutsideOfGrid
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%c%d", 'A'+column, row+1))
+
+	x -= float64(column) * cell
+	y -= float64(row) * cell
+
+	for i := 0; i < keypads; i++ {
+		cell /= 3
+
+		cx := clamp(int(math.Floor(x/cell)), 0, 2)
+		cy := clamp(int(math.Floor(y/cell)), 0, 2)
+
+		x -= float64(cx) * cell
+		y -= float64(cy) * cell
+
+		b.WriteString(fmt.Sprintf("-%d", (2-cy)*3+cx+1))
+	}
+
+	return b.String(), nil
+}
+
+func clamp(v int, min int, max int) int {
+	if v < min {
+		return min
+	}
+
+	if v > max {
+		return max
+	}
+
+	return v
+}
@@ -0,0 +1,73 @@
+package math
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGridParse(t *testing.T) {
+	grid := Grid{Size: 300}
+
+	tests := []struct {
+		name string
+		ref  string
+		want Vector3
+		err  error
+	}{
+		{"cell", "C4", Vector3{X: 750, Y: 1050}, nil},
+		{"two digit row", "C12", Vector3{X: 750, Y: 3450}, nil},
+		{"dashes", "C4-7-3-1", Vector3{X: 672.2222, Y: 994.4444}, nil},
+		{"spaces", "c4 7 3 1", Vector3{X: 672.2222, Y: 994.4444}, nil},
+		{"keypads without separators", "C4-731", Vector3{X: 672.2222, Y: 994.4444}, nil},
+		{"two digit row with keypads", "C12-5", Vector3{X: 750, Y: 3450}, nil},
+		{"row without separator", "C4731", Vector3{}, ErrInvalidGridRef},
+		{"empty", "", Vector3{}, ErrInvalidGridRef},
+		{"missing column", "4-7", Vector3{}, ErrInvalidGridRef},
+		{"row zero", "C0", Vector3{}, ErrInvalidGridRef},
+		{"keypad zero", "C4-0", Vector3{}, ErrInvalidGridRef},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := grid.Parse(test.ref)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Parse(%q) error = %v, want %v", test.ref, err, test.err)
+			}
+
+			if math.Abs(float64(got.X-test.want.X)) > 0.01 || math.Abs(float64(got.Y-test.want.Y)) > 0.01 {
+				t.Errorf("Parse(%q) = %v, want %v", test.ref, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGridFormat(t *testing.T) {
+	grid := Grid{Size: 300}
+
+	tests := []struct {
+		name    string
+		keypads int
+		want    string
+		err     error
+	}{
+		{"cell", 0, "C4", nil},
+		{"keypads", 3, "C4-7-3-1", nil},
+		{"max keypads", MaxKeypads, "C4-7-3-1-5-5", nil},
+		{"negative keypads", -1, "", ErrInvalidKeypads},
+		{"too many keypads", MaxKeypads + 1, "", ErrInvalidKeypads},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := grid.Format(Vector3{X: 672.2222, Y: 994.4444}, test.keypads)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Format(%d) error = %v, want %v", test.keypads, err, test.err)
+			}
+
+			if got != test.want {
+				t.Errorf("Format(%d) = %q, want %q", test.keypads, got, test.want)
+			}
+		})
+	}
+}
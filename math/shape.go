@@ -0,0 +1,46 @@
+package math
+
+import "math"
+
+// Ellipse returns a polygon of the points approximating the ellipse around the center. The along
+// radius lies in the direction of the bearing in radians, measured clockwise from north, the across
+// radius perpendicular to it. All points keep the height of the center.
+func Ellipse(center Vector3, bearing float64, alongRadius float64, acrossRadius float64, points int) []Vector3 {
+	polygon := make([]Vector3, 0, points)
+	for i := 0; i < points; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(points)
+
+		polygon = append(polygon, center.
+			Polar(bearing, alongRadius*math.Cos(angle)).
+			Polar(bearing+math.Pi/2, acrossRadius*math.Sin(angle)))
+	}
+
+	return polygon
+}
+
+// Circle returns a polygon of the points approximating the circle around the center.
+func Circle(center Vector3, radius float64, points int) []Vector3 {
+	return Ellipse(center, 0, radius, radius, points)
+}
+
+// Sector returns a polygon of the points approximating the ring sector around the center between
+// the inner and outer radius. The sector spans the arc in radians centered on the bearing. The
+// sector ends in the center if the inner radius is zero.
+func Sector(center Vector3, bearing float64, arc float64, innerRadius float64, outerRadius float64, points int) []Vector3 {
+	start := bearing - arc/2
+
+	polygon := make([]Vector3, 0, 2*points)
+	for i := 0; i < points; i++ {
+		polygon = append(polygon, center.Polar(start+arc*float64(i)/float64(points-1), outerRadius))
+	}
+
+	if innerRadius <= 0 {
+		return append(polygon, center)
+	}
+
+	for i := points - 1; i >= 0; i-- {
+		polygon = append(polygon, center.Polar(start+arc*float64(i)/float64(points-1), innerRadius))
+	}
+
+	return polygon
+}
@@ -1,5 +1,7 @@
 package math
 
+import "math"
+
 type Vector3 struct {
 	X float32
 	Y float32
@@ -13,3 +15,59 @@ func (v Vector3) Add(value Vector3) Vector3 {
 		v.Z + value.Z,
 	}
 }
+
+func (v Vector3) Sub(value Vector3) Vector3 {
+	return Vector3{
+		v.X - value.X,
+		v.Y - value.Y,
+		v.Z - value.Z,
+	}
+}
+
+func (v Vector3) Scale(factor float32) Vector3 {
+	return Vector3{
+		v.X * factor,
+		v.Y * factor,
+		v.Z * factor,
+	}
+}
+
+func (v Vector3) Length() float32 {
+	return float32(math.Sqrt(float64(v.X*v.X + v.Y*v.Y + v.Z*v.Z)))
+}
+
+// HorizontalLength returns the length of the vector projected onto the XY (map) plane.
+func (v Vector3) HorizontalLength() float32 {
+	return float32(math.Hypot(float64(v.X), float64(v.Y)))
+}
+
+// LineOffset returns the horizontal offset of moving along and across the line from from to to. A
+// positive along moves away from from, a positive across moves to the right as seen from from. It
+// returns false if both positions share the same horizontal position.
+func LineOffset(from Vector3, to Vector3, along float32, across float32) (Vector3, bool) {
+	delta := to.Sub(from)
+	delta.Z = 0
+
+	length := delta.HorizontalLength()
+	if length == 0 {
+		return Vector3{}, false
+	}
+
+	direction := delta.Scale(1 / length)
+	right := Vector3{
+		X: -direction.Y,
+		Y: direction.X,
+	}
+
+	return direction.Scale(along).Add(right.Scale(across)), true
+}
+
+// Polar returns the position at the horizontal distance from v in the direction of the bearing in
+// radians, measured clockwise from north. The height is kept.
+func (v Vector3) Polar(bearing float64, distance float64) Vector3 {
+	return Vector3{
+		X: v.X + float32(distance*math.Sin(bearing)),
+		Y: v.Y - float32(distance*math.Cos(bearing)),
+		Z: v.Z,
+	}
+}
@@ -0,0 +1,134 @@
+package pattern
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+var ErrInvalidPattern = errors.New("invalid fire pattern")
+var ErrTooManyAimPoints = errors.New("fire pattern has too many aim points")
+
+// MaxAimPoints is the largest number of aim points a pattern may produce.
+const MaxAimPoints = 64
+
+type Type int32
+
+const (
+	// ParallelSheaf spreads the aim points on a line across the bearing, the direction of fire.
+	ParallelSheaf Type = iota
+	// LinearSheaf spreads the aim points on a line along the bearing, e.g. along a treeline.
+	LinearSheaf
+	// Circle spreads the aim points on a circle around the center, starting at the bearing.
+	Circle
+	// CreepingBarrage repeats a parallel sheaf in steps moving away from the center along the bearing.
+	CreepingBarrage
+)
+
+// Pattern describes how aim points are spread around a center. Distances are in meters, the
+// bearing is in radians measured clockwise from north.
+type Pattern struct {
+	Type Type
+	// Points is the number of aim points of a sheaf or circle or of every step of a creeping barrage.
+	Points int
+	// Spacing is the distance between adjacent aim points of a sheaf or step.
+	Spacing float64
+	Bearing float64
+	Radius  float64
+	// Steps is the number of steps of a creeping barrage, each StepDistance further along the bearing.
+	Steps        int
+	StepDistance float64
+}
+
+// AimPoint is a position of a pattern. The step is the index of the step of a creeping barrage
+// and 0 for all other patterns.
+type AimPoint struct {
+	Position math.Vector3
+	Step     int
+}
+
+// AimPoints returns the aim points of the pattern around the center. All aim points keep the
+// height of the center.
+func (p Pattern) AimPoints(center math.Vector3) ([]AimPoint, error) {
+	if p.Points < 1 {
+		return nil, ErrInvalidPattern
+	}
+
+	switch p.Type {
+	case ParallelSheaf:
+		return p.line(center, p.Bearing+stdmath.Pi/2, 0)
+	case LinearSheaf:
+		return p.line(center, p.Bearing, 0)
+	case Circle:
+		return p.circle(center)
+	case CreepingBarrage:
+		return p.creepingBarrage(center)
+	default:
+		return nil, ErrInvalidPattern
+	}
+}
+
+// line spreads the aim points evenly on a line through the center in the direction of the bearing.
+func (p Pattern) line(center math.Vector3, bearing float64, step int) ([]AimPoint, error) {
+	if p.Points > MaxAimPoints {
+		return nil, ErrTooManyAimPoints
+	}
+
+	if p.Spacing <= 0 && p.Points > 1 {
+		return nil, ErrInvalidPattern
+	}
+
+	start := -p.Spacing * float64(p.Points-1) / 2
+
+	points := make([]AimPoint, 0, p.Points)
+	for i := 0; i < p.Points; i++ {
+		points = append(points, AimPoint{
+			Position: center.Polar(bearing, start+float64(i)*p.Spacing),
+			Step:     step,
+		})
+	}
+
+	return points, nil
+}
+
+func (p Pattern) circle(center math.Vector3) ([]AimPoint, error) {
+	if p.Points > MaxAimPoints {
+		return nil, ErrTooManyAimPoints
+	}
+
+	if p.Radius <= 0 {
+		return nil, ErrInvalidPattern
+	}
+
+	points := make([]AimPoint, 0, p.Points)
+	for i := 0; i < p.Points; i++ {
+		points = append(points, AimPoint{
+			Position: center.Polar(p.Bearing+2*stdmath.Pi*float64(i)/float64(p.Points), p.Radius),
+		})
+	}
+
+	return points, nil
+}
+
+// creepingBarrage starts with a parallel sheaf on the center and moves it along the bearing.
+func (p Pattern) creepingBarrage(center math.Vector3) ([]AimPoint, error) {
+	if p.Steps < 1 || p.StepDistance <= 0 {
+		return nil, ErrInvalidPattern
+	}
+
+	if p.Points*p.Steps > MaxAimPoints {
+		return nil, ErrTooManyAimPoints
+	}
+
+	points := make([]AimPoint, 0, p.Points*p.Steps)
+	for step := 0; step < p.Steps; step++ {
+		sheaf, err := p.line(center.Polar(p.Bearing, float64(step)*p.StepDistance), p.Bearing+stdmath.Pi/2, step)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, sheaf...)
+	}
+
+	return points, nil
+}
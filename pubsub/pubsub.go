@@ -11,6 +11,7 @@ type Publisher[V any] interface {
 
 type Subscriber[V any] interface {
 	Subscribe() Subscription[V]
+	Subscribers() int
 }
 
 type Subject[V any] interface {
@@ -35,6 +36,7 @@ type subscription[V any] struct {
 	unsubscribed bool
 
 	unsubscribeEventHandler eventhandler.EventHandler[*subscription[V], struct{}]
+	unsubscribeToken        eventhandler.Token
 }
 
 func (s *subscription[V]) Chan() <-chan V {
@@ -80,11 +82,18 @@ func (s *subject[V]) Subscribe() Subscription[V] {
 		unsubscribeEventHandler: eventhandler.New[*subscription[V], struct{}](),
 	}
 
-	sub.unsubscribeEventHandler.Add(s.unsubscribeEvent)
+	sub.unsubscribeToken = sub.unsubscribeEventHandler.Add(s.unsubscribeEvent)
 
 	return sub
 }
 
+func (s *subject[V]) Subscribers() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return len(s.subscribers)
+}
+
 func (s *subject[V]) unsubscribeEvent(sub *subscription[V], _ struct{}) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -100,7 +109,7 @@ func (s *subject[V]) unsubscribeEvent(sub *subscription[V], _ struct{}) {
 	s.subscribers[idx] = s.subscribers[len(s.subscribers)-1]
 	s.subscribers = s.subscribers[:len(s.subscribers)-1]
 
-	sub.unsubscribeEventHandler.Remove(s.unsubscribeEvent)
+	sub.unsubscribeEventHandler.Remove(sub.unsubscribeToken)
 }
 
 func (s *subject[V]) findChIndex(ch <-chan V, chs []chan V) int {
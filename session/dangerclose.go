@@ -0,0 +1,200 @@
+package session
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"sort"
+	"time"
+)
+
+var ErrDangerClose = errors.New("a friendly is within the danger close radius of the impact")
+
+// maxPositionTtl is the longest time a reported position is kept.
+const maxPositionTtl = 10 * time.Minute
+
+// DangerCloseWarning flags an active target with a friendly within its danger close radius. The
+// radius is the largest danger close radius of all active weapons in range of the target.
+type DangerCloseWarning struct {
+	Target   Target
+	User     User
+	Distance float64
+	Radius   float64
+}
+
+type dangerCloseKey struct {
+	targetId   TargetId
+	clientUuid string
+}
+
+// ReportPosition sets the in-game position of the user for the time to live.
+func (s *session) ReportPosition(user User, position math.Vector3, ttl time.Duration) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if ttl <= 0 || ttl > maxPositionTtl {
+		return errors.New("position time to live must be between 0 and 10 minutes")
+	}
+
+	if _, ok := s.users[user.ClientUuid().String()]; !ok {
+		return errors.New("user not found")
+	}
+
+	generation := user.setPosition(position, time.Now().Add(ttl))
+	time.AfterFunc(ttl, func() {
+		s.expirePosition(user, generation)
+	})
+
+	s.publish(SessionChange{
+		UserChanged: user,
+	})
+
+	s.checkDangerClose()
+
+	return nil
+}
+
+func (s *session) expirePosition(user User, generation uint64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !user.expirePosition(generation) {
+		return
+	}
+
+	if _, ok := s.users[user.ClientUuid().String()]; !ok {
+		return
+	}
+
+	s.publish(SessionChange{
+		UserChanged: user,
+	})
+
+	s.checkDangerClose()
+}
+
+func (s *session) DangerCloseWarnings() []DangerCloseWarning {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.currentDangerCloseWarnings()
+}
+
+func (s *session) BlockDangerCloseFire() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.blockDangerCloseFire
+}
+
+// SetBlockDangerCloseFire sets whether firing is refused if a friendly is within the danger close
+// radius of the impact.
+func (s *session) SetBlockDangerCloseFire(v bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.blockDangerCloseFire = v
+}
+
+// currentDangerCloseWarnings computes the warnings of all active targets sorted by target and
+// user. The caller must hold the session lock.
+func (s *session) currentDangerCloseWarnings() []DangerCloseWarning {
+	radii := make(map[TargetId]float64, len(s.targets))
+	for _, weapon := range s.weapons {
+		if !weapon.Active() {
+			continue
+		}
+
+		for _, solution := range weapon.Solutions() {
+			if solution.Solution.InRange() && solution.Solution.Dispersion.DangerCloseRadius > radii[solution.TargetId] {
+				radii[solution.TargetId] = solution.Solution.Dispersion.DangerCloseRadius
+			}
+		}
+	}
+
+	warnings := make([]DangerCloseWarning, 0)
+	for _, target := range s.targets {
+		radius, ok := radii[target.Id()]
+		if !ok || !target.Active() {
+			continue
+		}
+
+		for _, user := range s.users {
+			position, ok := user.Position()
+			if !ok {
+				continue
+			}
+
+			distance := float64(position.Sub(target.Position()).HorizontalLength())
+			if distance > radius {
+				continue
+			}
+
+			warnings = append(warnings, DangerCloseWarning{
+				Target:   target,
+				User:     user,
+				Distance: distance,
+				Radius:   radius,
+			})
+		}
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Target.Id() != warnings[j].Target.Id() {
+			return warnings[i].Target.Id() < warnings[j].Target.Id()
+		}
+
+		return warnings[i].User.ClientUuid().String() < warnings[j].User.ClientUuid().String()
+	})
+
+	return warnings
+}
+
+// checkDangerClose publishes the warnings which have been raised or cleared since the last check.
+// The caller must hold the session lock.
+func (s *session) checkDangerClose() {
+	s.dangerCloseMtx.Lock()
+	defer s.dangerCloseMtx.Unlock()
+
+	warnings := s.currentDangerCloseWarnings()
+
+	current := make(map[dangerCloseKey]DangerCloseWarning, len(warnings))
+	for _, warning := range warnings {
+		key := dangerCloseKey{warning.Target.Id(), warning.User.ClientUuid().String()}
+		current[key] = warning
+
+		if _, ok := s.dangerClose[key]; !ok {
+			warning := warning
+			s.publish(SessionChange{
+				DangerCloseRaised: &warning,
+			})
+		}
+	}
+
+	for key, warning := range s.dangerClose {
+		if _, ok := current[key]; !ok {
+			warning := warning
+			s.publish(SessionChange{
+				DangerCloseCleared: &warning,
+			})
+		}
+	}
+
+	s.dangerClose = current
+}
+
+// checkFriendlies returns ErrDangerClose if firing on the impact with the radius is blocked by a
+// friendly. The caller must hold the session lock.
+func (s *session) checkFriendlies(impact math.Vector3, radius float64) error {
+	if !s.blockDangerCloseFire {
+		return nil
+	}
+
+	for _, user := range s.users {
+		position, ok := user.Position()
+		if ok && float64(position.Sub(impact).HorizontalLength()) <= radius {
+			return ErrDangerClose
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"sort"
+	"time"
+)
+
+type EffectId int32
+
+// Effect is left on the impact of a round, e.g. a smoke screen. It is removed once it has expired.
+// Effects are not persisted.
+type Effect struct {
+	Id       EffectId
+	Kind     armory.AmmunitionKind
+	WeaponId WeaponId
+	Position math.Vector3
+	// Radius is the radius of the effect in meters.
+	Radius    float64
+	SpawnedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (s *session) nextEffectId() EffectId {
+	s.effectIdCounter++
+	return s.effectIdCounter
+}
+
+func (s *session) Effects() []Effect {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	effects := make([]Effect, 0, len(s.effects))
+	for _, effect := range s.effects {
+		effects = append(effects, effect)
+	}
+
+	sort.Slice(effects, func(i, j int) bool {
+		return effects[i].Id < effects[j].Id
+	})
+
+	return effects
+}
+
+// scheduleEffect spawns the effect of the ammunition of the shot on its impact and removes it once
+// it has expired. The caller must hold the session lock.
+func (s *session) scheduleEffect(shot Shot) {
+	if shot.Ammunition.EffectLifetime <= 0 {
+		return
+	}
+
+	effect := Effect{
+		Id:        s.nextEffectId(),
+		Kind:      shot.Ammunition.Kind,
+		WeaponId:  shot.Weapon.Id(),
+		Position:  shot.Impact.Position,
+		Radius:    shot.Ammunition.EffectRadius,
+		SpawnedAt: shot.ImpactAt,
+		ExpiresAt: shot.ImpactAt.Add(seconds(shot.Ammunition.EffectLifetime)),
+	}
+
+	s.effectTimers[effect.Id] = time.AfterFunc(time.Until(effect.SpawnedAt), func() {
+		s.spawnEffect(effect)
+	})
+}
+
+func (s *session) spawnEffect(effect Effect) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// The effect has been cleared while its round was in flight.
+	if _, ok := s.effectTimers[effect.Id]; !ok {
+		return
+	}
+
+	s.effects[effect.Id] = effect
+	s.effectTimers[effect.Id] = time.AfterFunc(time.Until(effect.ExpiresAt), func() {
+		s.expireEffect(effect.Id)
+	})
+
+	s.publish(SessionChange{
+		EffectAdded: &effect,
+	})
+}
+
+func (s *session) expireEffect(id EffectId) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	effect, ok := s.effects[id]
+	if !ok {
+		return
+	}
+
+	delete(s.effects, id)
+	delete(s.effectTimers, id)
+
+	s.publish(SessionChange{
+		EffectRemoved: &effect,
+	})
+}
+
+// clearEffects stops all pending and active effects without publishing their removal. The caller
+// must hold the session lock.
+func (s *session) clearEffects() {
+	for id, timer := range s.effectTimers {
+		timer.Stop()
+		delete(s.effectTimers, id)
+	}
+
+	for id := range s.effects {
+		delete(s.effects, id)
+	}
+}
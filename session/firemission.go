@@ -0,0 +1,308 @@
+package session
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/eventhandler"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrInvalidStateTransition = errors.New("invalid fire mission state transition")
+
+type FireMissionId int32
+
+type FireMissionState int32
+
+const (
+	RequestedFireMissionState FireMissionState = iota
+	AcknowledgedFireMissionState
+	FiringFireMissionState
+	SplashFireMissionState
+	CompleteFireMissionState
+	CancelledFireMissionState
+)
+
+// fireMissionTransitions lists the states a fire mission may move to from a state. Complete and
+// cancelled missions are final, a mission may be fired again after the splash of a previous salvo.
+var fireMissionTransitions = map[FireMissionState][]FireMissionState{
+	RequestedFireMissionState:    {AcknowledgedFireMissionState, CancelledFireMissionState},
+	AcknowledgedFireMissionState: {FiringFireMissionState, CancelledFireMissionState},
+	FiringFireMissionState:       {SplashFireMissionState, CompleteFireMissionState, CancelledFireMissionState},
+	SplashFireMissionState:       {FiringFireMissionState, CompleteFireMissionState, CancelledFireMissionState},
+}
+
+func (s FireMissionState) CanTransitionTo(state FireMissionState) bool {
+	for _, next := range fireMissionTransitions[s] {
+		if next == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FireMission is a call for fire of a number of rounds by one or more weapons on one or more targets.
+type FireMission interface {
+	Id() FireMissionId
+	Targets() []Target
+	Weapons() []Weapon
+	Rounds() int
+	RequestedBy() User
+	RequestedAt() time.Time
+	AcknowledgedBy() User
+	State() FireMissionState
+	SetState(user User, state FireMissionState) error
+	StateChanged() eventhandler.Event[FireMission, FireMissionStateChangedEventArgs]
+
+	removeTarget(id TargetId) bool
+	removeWeapon(id WeaponId) bool
+}
+
+type FireMissionStateChangedEventArgs struct {
+	OldState FireMissionState
+	NewState FireMissionState
+	User     User
+}
+
+type fireMission struct {
+	id             FireMissionId
+	targets        []Target
+	weapons        []Weapon
+	rounds         int
+	requestedBy    User
+	requestedAt    time.Time
+	acknowledgedBy User
+	state          FireMissionState
+
+	stateEventHandler eventhandler.EventHandler[FireMission, FireMissionStateChangedEventArgs]
+
+	mtx sync.RWMutex
+}
+
+func (f *fireMission) Id() FireMissionId {
+	return f.id
+}
+
+func (f *fireMission) Targets() []Target {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	return append([]Target(nil), f.targets...)
+}
+
+func (f *fireMission) Weapons() []Weapon {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	return append([]Weapon(nil), f.weapons...)
+}
+
+func (f *fireMission) Rounds() int {
+	return f.rounds
+}
+
+func (f *fireMission) RequestedBy() User {
+	return f.requestedBy
+}
+
+func (f *fireMission) RequestedAt() time.Time {
+	return f.requestedAt
+}
+
+func (f *fireMission) AcknowledgedBy() User {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	return f.acknowledgedBy
+}
+
+func (f *fireMission) State() FireMissionState {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	return f.state
+}
+
+// SetState moves the fire mission to the state on behalf of the user. The user acknowledging
+// the mission is recorded.
+func (f *fireMission) SetState(u User, state FireMissionState) error {
+	f.mtx.Lock()
+
+	old := f.state
+	if !old.CanTransitionTo(state) {
+		f.mtx.Unlock()
+		return ErrInvalidStateTransition
+	}
+
+	f.state = state
+	if state == AcknowledgedFireMissionState {
+		f.acknowledgedBy = u
+	}
+
+	f.mtx.Unlock()
+
+	f.stateEventHandler.Invoke(f, FireMissionStateChangedEventArgs{
+		OldState: old,
+		NewState: state,
+		User:     u,
+	})
+
+	return nil
+}
+
+func (f *fireMission) StateChanged() eventhandler.Event[FireMission, FireMissionStateChangedEventArgs] {
+	return f.stateEventHandler
+}
+
+func (f *fireMission) removeTarget(id TargetId) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for i, target := range f.targets {
+		if target.Id() == id {
+			f.targets = append(f.targets[:i], f.targets[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fireMission) removeWeapon(id WeaponId) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for i, weapon := range f.weapons {
+		if weapon.Id() == id {
+			f.weapons = append(f.weapons[:i], f.weapons[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func newFireMission(id FireMissionId, targets []Target, weapons []Weapon, rounds int, requestedBy User, requestedAt time.Time) FireMission {
+	return &fireMission{
+		id,
+		targets,
+		weapons,
+		rounds,
+		requestedBy,
+		requestedAt,
+		nil,
+		RequestedFireMissionState,
+		eventhandler.New[FireMission, FireMissionStateChangedEventArgs](),
+		sync.RWMutex{},
+	}
+}
+
+func (s *session) nextFireMissionId() FireMissionId {
+	s.fireMissionIdCounter++
+	return s.fireMissionIdCounter
+}
+
+func (s *session) FireMissions() []FireMission {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	fireMissions := slice.MapValuesToSlice(s.fireMissions)
+	sort.Slice(fireMissions, func(i, j int) bool {
+		return fireMissions[i].Id() < fireMissions[j].Id()
+	})
+
+	return fireMissions
+}
+
+func (s *session) FireMission(id FireMissionId) (FireMission, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	fireMission, ok := s.fireMissions[id]
+	if !ok {
+		return nil, errors.New("fire mission not found")
+	}
+
+	return fireMission, nil
+}
+
+// RequestFireMission requests a fire mission of the user on the targets with the weapons.
+func (s *session) RequestFireMission(user User, targetIds []TargetId, weaponIds []WeaponId, rounds int) (FireMission, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(s.fireMissions) >= s.maxFireMissions {
+		return nil, errors.New("maximum fire missions per sessions reached")
+	}
+
+	if len(targetIds) == 0 {
+		return nil, errors.New("fire mission needs at least one target")
+	}
+
+	if len(weaponIds) == 0 {
+		return nil, errors.New("fire mission needs at least one weapon")
+	}
+
+	if rounds < 1 {
+		return nil, errors.New("fire mission needs at least one round")
+	}
+
+	targets := make([]Target, 0, len(targetIds))
+	for _, id := range targetIds {
+		target, ok := s.targets[id]
+		if !ok {
+			return nil, errors.New("target not found")
+		}
+
+		targets = append(targets, target)
+	}
+
+	weapons := make([]Weapon, 0, len(weaponIds))
+	for _, id := range weaponIds {
+		weapon, ok := s.weapons[id]
+		if !ok {
+			return nil, errors.New("weapon not found")
+		}
+
+		weapons = append(weapons, weapon)
+	}
+
+	fireMission := newFireMission(s.nextFireMissionId(), targets, weapons, rounds, user, time.Now())
+	s.attach(fireMission, handle(fireMission.StateChanged(), s.fireMissionStateChanged))
+
+	s.fireMissions[fireMission.Id()] = fireMission
+
+	s.publish(SessionChange{
+		FireMissionAdded: fireMission,
+	})
+
+	return fireMission, nil
+}
+
+func (s *session) RemoveFireMission(id FireMissionId) (FireMission, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	fireMission, ok := s.fireMissions[id]
+	if !ok {
+		return nil, errors.New("fire mission is already removed")
+	}
+
+	delete(s.fireMissions, id)
+
+	s.detach(fireMission)
+
+	s.publish(SessionChange{
+		FireMissionRemoved: fireMission,
+	})
+
+	return fireMission, nil
+}
+
+func (s *session) fireMissionStateChanged(sender FireMission, args FireMissionStateChangedEventArgs) {
+	s.publish(SessionChange{
+		FireMissionChanged: sender,
+	})
+}
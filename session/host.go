@@ -0,0 +1,131 @@
+package session
+
+import (
+	"errors"
+	"github.com/google/uuid"
+)
+
+func (s *session) HostUuid() uuid.UUID {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.hostUuid
+}
+
+func (s *session) Host() User {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.users[s.hostUuid.String()]
+}
+
+func (s *session) IsHost(clientUuid uuid.UUID) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.hostUuid == clientUuid
+}
+
+func (s *session) TransferHost(clientUuid uuid.UUID) (User, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	user, ok := s.users[clientUuid.String()]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+
+	s.hostUuid = clientUuid
+	s.promoteHost(user)
+
+	s.publish(SessionChange{
+		HostChanged: user,
+	})
+
+	return user, nil
+}
+
+func (s *session) Kick(clientUuid uuid.UUID) (User, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	user, err := s.quit(clientUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(SessionChange{
+		UserLeft:   user,
+		UserKicked: user,
+	})
+
+	s.handOverHost(user)
+
+	return user, nil
+}
+
+// Ban kicks the user if joined and prevents the client from joining again.
+func (s *session) Ban(clientUuid uuid.UUID) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := s.banned[clientUuid.String()]; ok {
+		return errors.New("user is already banned")
+	}
+
+	s.banned[clientUuid.String()] = struct{}{}
+
+	if _, ok := s.users[clientUuid.String()]; !ok {
+		return nil
+	}
+
+	user, err := s.quit(clientUuid)
+	if err != nil {
+		return err
+	}
+
+	s.publish(SessionChange{
+		UserLeft:   user,
+		UserKicked: user,
+		UserBanned: user,
+	})
+
+	s.handOverHost(user)
+
+	return nil
+}
+
+// handOverHost passes the host role to the longest present user if the leaving user was the host.
+// The caller must hold the session lock.
+func (s *session) handOverHost(leaving User) {
+	if leaving.ClientUuid() != s.hostUuid {
+		return
+	}
+
+	var next User
+	for _, user := range s.users {
+		if next == nil || user.JoinedAt().Before(next.JoinedAt()) {
+			next = user
+		}
+	}
+
+	if next == nil {
+		s.hostUuid = uuid.Nil
+		return
+	}
+
+	s.hostUuid = next.ClientUuid()
+	s.promoteHost(next)
+
+	s.publish(SessionChange{
+		HostChanged: next,
+	})
+}
+
+// promoteHost gives the new host the commander role, so that the host holds the permissions of
+// the role as well. The caller must hold the session lock.
+func (s *session) promoteHost(host User) {
+	if host.Role() != CommanderRole {
+		host.SetRole(CommanderRole)
+	}
+}
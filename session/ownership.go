@@ -0,0 +1,233 @@
+package session
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrAlreadyOwned = errors.New("already owned by another user")
+var ErrNotOwned = errors.New("not owned by any user")
+var ErrNotOwner = errors.New("user is not the owner")
+var ErrNoHandoverRequested = errors.New("no handover requested")
+
+type HandoverChangedEventArgs struct {
+	OldRequester User
+	NewRequester User
+}
+
+// ownership holds the owner of a target or weapon, its lease and a pending handover request.
+//
+// A lease of zero never expires. Whenever a lease is started, the generation is incremented so
+// that timers of replaced leases can be detected and ignored.
+type ownership struct {
+	owner          User
+	lease          time.Duration
+	leaseExpiresAt time.Time
+	generation     uint64
+	requester      User
+
+	mtx sync.RWMutex
+}
+
+func (o *ownership) Owner() User {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	return o.owner
+}
+
+func (o *ownership) LeaseExpiresAt() (time.Time, bool) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	return o.leaseExpiresAt, !o.leaseExpiresAt.IsZero()
+}
+
+func (o *ownership) HandoverRequester() User {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	return o.requester
+}
+
+// acquire makes the user the owner or renews the lease if the user already owns it.
+func (o *ownership) acquire(user User, lease time.Duration, expire func(generation uint64)) (User, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	old := o.owner
+	if old != nil && old.ClientUuid() != user.ClientUuid() {
+		return nil, ErrAlreadyOwned
+	}
+
+	requester := o.requester
+
+	o.set(user, lease, expire)
+
+	if old != nil {
+		o.requester = requester
+	}
+
+	return old, nil
+}
+
+func (o *ownership) release(user User) (User, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	old := o.owner
+	if old == nil {
+		return nil, ErrNotOwned
+	}
+
+	if old.ClientUuid() != user.ClientUuid() {
+		return nil, ErrNotOwner
+	}
+
+	o.set(nil, 0, nil)
+
+	return old, nil
+}
+
+// force replaces the owner regardless of the current owner without a lease.
+func (o *ownership) force(user User) User {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	old := o.owner
+
+	o.set(user, 0, nil)
+
+	return old
+}
+
+// expire releases the owner if the lease of the given generation is still the current one.
+func (o *ownership) expire(generation uint64) (User, bool) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.generation != generation || o.owner == nil {
+		return nil, false
+	}
+
+	old := o.owner
+
+	o.set(nil, 0, nil)
+
+	return old, true
+}
+
+// leave releases the owner if it is the user and dismisses a handover requested by the user. It
+// returns the released owner, if any, and whether a handover request has been dismissed.
+func (o *ownership) leave(user User) (User, bool) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.owner != nil && o.owner.ClientUuid() == user.ClientUuid() {
+		old := o.owner
+		o.set(nil, 0, nil)
+
+		return old, false
+	}
+
+	if o.requester != nil && o.requester.ClientUuid() == user.ClientUuid() {
+		o.requester = nil
+
+		return nil, true
+	}
+
+	return nil, false
+}
+
+func (o *ownership) requestHandover(user User) (User, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.owner == nil {
+		return nil, ErrNotOwned
+	}
+
+	if o.owner.ClientUuid() == user.ClientUuid() {
+		return nil, errors.New("user is already the owner")
+	}
+
+	old := o.requester
+	o.requester = user
+
+	return old, nil
+}
+
+// respondHandover hands the ownership including the lease duration over to the requester if accepted,
+// otherwise the request is dismissed. It returns the previous owner and the requester.
+func (o *ownership) respondHandover(user User, accept bool, expire func(generation uint64)) (User, User, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.owner == nil || o.owner.ClientUuid() != user.ClientUuid() {
+		return nil, nil, ErrNotOwner
+	}
+
+	requester := o.requester
+	if requester == nil {
+		return nil, nil, ErrNoHandoverRequested
+	}
+
+	old := o.owner
+
+	if accept {
+		o.set(requester, o.lease, expire)
+	} else {
+		o.requester = nil
+	}
+
+	return old, requester, nil
+}
+
+// set replaces the owner and starts a new lease. The caller must hold the lock.
+func (o *ownership) set(user User, lease time.Duration, expire func(generation uint64)) {
+	o.generation++
+
+	o.owner = user
+	o.lease = lease
+	o.leaseExpiresAt = time.Time{}
+	o.requester = nil
+
+	if user == nil || lease <= 0 || expire == nil {
+		return
+	}
+
+	generation := o.generation
+
+	o.leaseExpiresAt = time.Now().Add(lease)
+	time.AfterFunc(lease, func() {
+		expire(generation)
+	})
+}
+
+func (o *ownership) snapshot() (User, time.Duration, time.Time) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	return o.owner, o.lease, o.leaseExpiresAt
+}
+
+// restore sets the owner of a snapshot. An already expired lease leaves it without an owner.
+func (o *ownership) restore(user User, lease time.Duration, leaseExpiresAt time.Time, expire func(generation uint64)) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if leaseExpiresAt.IsZero() {
+		o.set(user, 0, nil)
+		return
+	}
+
+	remaining := time.Until(leaseExpiresAt)
+	if remaining <= 0 {
+		o.set(nil, 0, nil)
+		return
+	}
+
+	o.set(user, remaining, expire)
+	o.lease = lease
+}
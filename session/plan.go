@@ -0,0 +1,188 @@
+package session
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/assignment"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	stdmath "math"
+	"sort"
+	"time"
+)
+
+// maxPlanTargets bounds the number of active targets of a plan, as the cost matrix grows with the
+// square of the number of targets and is built while the session is locked.
+const maxPlanTargets = 64
+
+var ErrTooManyPlanTargets = errors.New("too many active targets to plan assignments for")
+
+// PlannedTarget is a target in the queue of a weapon.
+type PlannedTarget struct {
+	Target   Target
+	Solution ballistics.Solution
+	// EngagedAfter is the time from the start of the plan until the round impacts on the target.
+	EngagedAfter time.Duration
+}
+
+// WeaponAssignment is the queue of targets a weapon engages in order.
+type WeaponAssignment struct {
+	Weapon  Weapon
+	Targets []PlannedTarget
+}
+
+// Plan assigns the active targets to the active weapons. Every weapon lays on a target, fires and
+// waits for the splash before laying on its next target. The plan is not updated when targets or
+// weapons change afterwards.
+type Plan struct {
+	Assignments []WeaponAssignment
+	// Unassigned holds the targets out of range or out of the sector of every active weapon.
+	Unassigned []Target
+	// TotalTimeToEngage is the sum of the times until the rounds impact on the assigned targets.
+	TotalTimeToEngage time.Duration
+	LayTime           time.Duration
+	CreatedAt         time.Time
+}
+
+func (s *session) Plan() (Plan, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	if s.plan == nil {
+		return Plan{}, false
+	}
+
+	return *s.plan, true
+}
+
+// PlanAssignments assigns the active targets to the active weapons in range, so that the total
+// time to engage all targets is minimal, and publishes the plan. The lay time is the time a weapon
+// needs to lay on a target before firing.
+func (s *session) PlanAssignments(layTime time.Duration) (Plan, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if layTime < 0 {
+		return Plan{}, errors.New("lay time must not be negative")
+	}
+
+	weapons := make([]Weapon, 0, len(s.weapons))
+	for _, weapon := range s.weapons {
+		if weapon.Active() {
+			weapons = append(weapons, weapon)
+		}
+	}
+
+	if len(weapons) == 0 {
+		return Plan{}, errors.New("no active weapons to assign targets to")
+	}
+
+	sort.Slice(weapons, func(i, j int) bool {
+		return weapons[i].Id() < weapons[j].Id()
+	})
+
+	targets := make([]Target, 0, len(s.targets))
+	for _, target := range s.targets {
+		if target.Active() {
+			targets = append(targets, target)
+		}
+	}
+
+	if len(targets) > maxPlanTargets {
+		return Plan{}, ErrTooManyPlanTargets
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Id() < targets[j].Id()
+	})
+
+	plan := Plan{
+		Assignments: make([]WeaponAssignment, len(weapons)),
+		Unassigned:  make([]Target, 0),
+		LayTime:     layTime,
+		CreatedAt:   time.Now(),
+	}
+
+	for i, weapon := range weapons {
+		plan.Assignments[i] = WeaponAssignment{
+			Weapon:  weapon,
+			Targets: make([]PlannedTarget, 0),
+		}
+	}
+
+	solutions := make([][]ballistics.Solution, 0, len(targets))
+	reachable := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		row := make([]ballistics.Solution, len(weapons))
+		inRange := false
+
+		for i, weapon := range weapons {
+			row[i] = weapon.Solve(target.Position())
+			inRange = inRange || row[i].InRange()
+		}
+
+		if !inRange {
+			plan.Unassigned = append(plan.Unassigned, target)
+			continue
+		}
+
+		solutions = append(solutions, row)
+		reachable = append(reachable, target)
+	}
+
+	// The total time to engage is minimized by assigning every target to a slot of a weapon. A
+	// target in the k-th slot from the end of the queue of a weapon delays itself and the k-1
+	// targets after it, so its cost is k times the time the weapon needs to engage it.
+	n := len(reachable)
+	costs := make([][]float64, n)
+	for t, row := range solutions {
+		costs[t] = make([]float64, len(weapons)*n)
+
+		for w, solution := range row {
+			duration := stdmath.Inf(1)
+			if solution.InRange() {
+				duration = layTime.Seconds() + solution.TimeOfFlight
+			}
+
+			for k := 0; k < n; k++ {
+				costs[t][w*n+k] = float64(k+1) * duration
+			}
+		}
+	}
+
+	slots, ok := assignment.Solve(costs)
+	if !ok {
+		return Plan{}, errors.New("targets could not be assigned")
+	}
+
+	// Slots are counted from the end of the queue, so the target in the highest slot goes first.
+	order := make([]int, n)
+	for t := range order {
+		order[t] = t
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return slots[order[i]]%n > slots[order[j]]%n
+	})
+
+	engagedAfter := make([]time.Duration, len(weapons))
+	for _, t := range order {
+		w := slots[t] / n
+		solution := solutions[t][w]
+
+		engagedAfter[w] += layTime + seconds(solution.TimeOfFlight)
+		plan.TotalTimeToEngage += engagedAfter[w]
+
+		plan.Assignments[w].Targets = append(plan.Assignments[w].Targets, PlannedTarget{
+			Target:       reachable[t],
+			Solution:     solution,
+			EngagedAfter: engagedAfter[w],
+		})
+	}
+
+	s.plan = &plan
+
+	s.publish(SessionChange{
+		PlanChanged: &plan,
+	})
+
+	return plan, nil
+}
@@ -0,0 +1,52 @@
+package session
+
+type Role int32
+
+const (
+	CommanderRole Role = iota
+	SpotterRole
+	GunnerRole
+	SpectatorRole
+)
+
+type Permission int32
+
+const (
+	// ManageTargetsPermission allows adding, changing and acquiring targets.
+	ManageTargetsPermission Permission = iota
+	// ManageWeaponsPermission allows adding, changing and acquiring weapons.
+	ManageWeaponsPermission
+	// ManageRolesPermission allows changing the roles of other users.
+	ManageRolesPermission
+	// OverrideOwnershipPermission allows changing targets and weapons owned by other users.
+	OverrideOwnershipPermission
+	// ManageSessionPermission allows changing the settings of the session like the map.
+	ManageSessionPermission
+)
+
+var rolePermissions = map[Role]map[Permission]struct{}{
+	CommanderRole: {
+		ManageTargetsPermission:     {},
+		ManageWeaponsPermission:     {},
+		ManageRolesPermission:       {},
+		OverrideOwnershipPermission: {},
+		ManageSessionPermission:     {},
+	},
+	SpotterRole: {
+		ManageTargetsPermission: {},
+	},
+	GunnerRole: {
+		ManageWeaponsPermission: {},
+	},
+	SpectatorRole: {},
+}
+
+func (r Role) Can(permission Permission) bool {
+	_, ok := rolePermissions[r][permission]
+	return ok
+}
+
+type RoleChangedEventArgs struct {
+	OldRole Role
+	NewRole Role
+}
@@ -3,21 +3,58 @@ package session
 import (
 	"errors"
 	"github.com/google/uuid"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/eventhandler"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/pubsub"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
+	"sort"
 	"sync"
+	"time"
 )
 
 type SessionChange struct {
+	// Timestamp is the server time the change has been published at.
+	Timestamp time.Time
+
 	UserLeft      User
 	UserJoined    User
 	UserChanged   User
+	UserKicked    User
+	UserBanned    User
+	HostChanged   User
 	TargetAdded   Target
 	TargetChanged Target
 	TargetRemoved Target
 	WeaponAdded   Weapon
 	WeaponChanged Weapon
 	WeaponRemoved Weapon
+
+	WeaponSolutionsChanged []Weapon
+
+	FireMissionAdded   FireMission
+	FireMissionChanged FireMission
+	FireMissionRemoved FireMission
+
+	TimeOnTargetScheduled TimeOnTarget
+	TimeOnTargetCountdown *TimeOnTargetCountdown
+	TimeOnTargetEnded     TimeOnTarget
+
+	ShotFired *Shot
+
+	EffectAdded   *Effect
+	EffectRemoved *Effect
+
+	DangerCloseRaised  *DangerCloseWarning
+	DangerCloseCleared *DangerCloseWarning
+
+	PlanChanged *Plan
+
+	MapChanged *gamemap.Map
+
+	SessionClosed Session
 }
 
 type Session interface {
@@ -25,17 +62,41 @@ type Session interface {
 
 	Uuid() uuid.UUID
 
+	LastActivity() time.Time
+	Touch()
+	EmptySince() (time.Time, bool)
+	Close()
+
+	Snapshot() Snapshot
+
 	MaxUsers() int
 	MaxWeapons() int
 	MaxTargets() int
+	MaxFireMissions() int
+	MaxTimeOnTargets() int
 
 	SetMaxUsers(v int)
 	SetMaxWeapons(v int)
 	SetMaxTargets(v int)
+	SetMaxFireMissions(v int)
+	SetMaxTimeOnTargets(v int)
 
 	Join(clientUuid uuid.UUID) (User, error)
 	Quit(clientUuid uuid.UUID) (User, error)
 
+	HostUuid() uuid.UUID
+	Host() User
+	IsHost(clientUuid uuid.UUID) bool
+	TransferHost(clientUuid uuid.UUID) (User, error)
+	Kick(clientUuid uuid.UUID) (User, error)
+	Ban(clientUuid uuid.UUID) error
+
+	DefaultRole() Role
+	SetDefaultRole(role Role)
+
+	Map() (gamemap.Map, bool)
+	SetMap(m gamemap.Map, heightmap terrain.Heightmap)
+
 	Users() []User
 	Weapons() []Weapon
 	Targets() []Target
@@ -44,29 +105,93 @@ type Session interface {
 	Weapon(id WeaponId) (Weapon, error)
 	Target(id TargetId) (Target, error)
 
-	AddWeapon(weaponType WeaponType) (Weapon, error)
+	AddWeapon(weaponType armory.WeaponType) (Weapon, error)
 	AddTarget() (Target, error)
 
 	RemoveWeapon(id WeaponId) (Weapon, error)
 	RemoveTarget(id TargetId) (Target, error)
+
+	AddChildTargets(parentId TargetId, positions []math.Vector3) ([]Target, error)
+
+	FireMissions() []FireMission
+	FireMission(id FireMissionId) (FireMission, error)
+	RequestFireMission(user User, targetIds []TargetId, weaponIds []WeaponId, rounds int) (FireMission, error)
+	RemoveFireMission(id FireMissionId) (FireMission, error)
+
+	TimeOnTargets() []TimeOnTarget
+	TimeOnTarget(id TimeOnTargetId) (TimeOnTarget, error)
+	ScheduleTimeOnTarget(user User, targetId TargetId, weaponIds []WeaponId, delay time.Duration) (TimeOnTarget, error)
+	CancelTimeOnTarget(id TimeOnTargetId) (TimeOnTarget, error)
+
+	Fire(user User, weaponId WeaponId, targetId *TargetId) (Shot, error)
+
+	Effects() []Effect
+
+	ReportPosition(user User, position math.Vector3, ttl time.Duration) error
+	DangerCloseWarnings() []DangerCloseWarning
+	BlockDangerCloseFire() bool
+	SetBlockDangerCloseFire(v bool)
+
+	Plan() (Plan, bool)
+	PlanAssignments(layTime time.Duration) (Plan, error)
+
+	PredictImpact(id WeaponId, elevation float64, azimuth float64) (Weapon, error)
+	ClearPredictedImpact(id WeaponId) (Weapon, error)
 }
 
 type session struct {
-	uuid uuid.UUID
+	uuid     uuid.UUID
+	hostUuid uuid.UUID
 
-	maxUsers   int
-	maxWeapons int
-	maxTargets int
+	maxUsers         int
+	maxWeapons       int
+	maxTargets       int
+	maxFireMissions  int
+	maxTimeOnTargets int
 
 	weaponIdCounter WeaponId
 	targetIdCounter TargetId
 
+	fireMissionIdCounter  FireMissionId
+	timeOnTargetIdCounter TimeOnTargetId
+	effectIdCounter       EffectId
+
 	users   map[string]User
 	weapons map[WeaponId]Weapon
 	targets map[TargetId]Target
 
+	fireMissions  map[FireMissionId]FireMission
+	timeOnTargets map[TimeOnTargetId]TimeOnTarget
+
+	effects      map[EffectId]Effect
+	effectTimers map[EffectId]*time.Timer
+
+	plan *Plan
+
+	// dangerClose holds the warnings of the last check. It is guarded by its own lock, as checks
+	// also run while only holding the read lock of the session.
+	dangerClose          map[dangerCloseKey]DangerCloseWarning
+	dangerCloseMtx       sync.Mutex
+	blockDangerCloseFire bool
+
+	banned map[string]struct{}
+
+	// handlers holds the functions removing the event handlers of the session from every user,
+	// weapon, target and fire mission.
+	handlers map[any][]func()
+
+	defaultRole Role
+
+	gameMap   *gamemap.Map
+	heightmap terrain.Heightmap
+
 	mtx sync.RWMutex
 
+	lastActivity time.Time
+	emptySince   time.Time
+
+	activityMtx sync.Mutex
+
 	updateSubject pubsub.Subject[SessionChange]
 }
 
@@ -74,6 +199,51 @@ func (s *session) Uuid() uuid.UUID {
 	return s.uuid
 }
 
+func (s *session) LastActivity() time.Time {
+	s.activityMtx.Lock()
+	defer s.activityMtx.Unlock()
+
+	return s.lastActivity
+}
+
+func (s *session) Touch() {
+	s.activityMtx.Lock()
+	defer s.activityMtx.Unlock()
+
+	s.lastActivity = time.Now()
+}
+
+func (s *session) EmptySince() (time.Time, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.emptySince, len(s.users) == 0
+}
+
+// Close cancels all times on target, clears all effects and notifies all subscribers that the session is about to be removed.
+func (s *session) Close() {
+	s.mtx.Lock()
+	s.cancelTimeOnTargets(func(timeOnTarget TimeOnTarget) bool {
+		return true
+	})
+	s.clearEffects()
+	s.mtx.Unlock()
+
+	s.updateSubject.Publish(SessionChange{
+		Timestamp:     time.Now(),
+		SessionClosed: s,
+	})
+}
+
+// publish touches the session and publishes the change stamped with the current time to all subscribers.
+func (s *session) publish(change SessionChange) {
+	s.Touch()
+
+	change.Timestamp = time.Now()
+
+	s.updateSubject.Publish(change)
+}
+
 func (s *session) nextWeaponId() WeaponId {
 	s.weaponIdCounter++
 	return s.weaponIdCounter
@@ -84,7 +254,7 @@ func (s *session) nextTargetId() TargetId {
 	return s.targetIdCounter
 }
 
-func (s *session) AddWeapon(weaponType WeaponType) (Weapon, error) {
+func (s *session) AddWeapon(weaponType armory.WeaponType) (Weapon, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
@@ -95,37 +265,93 @@ func (s *session) AddWeapon(weaponType WeaponType) (Weapon, error) {
 	id := s.nextWeaponId()
 	weapon := newWeapon(id, weaponType)
 
-	weapon.PositionChanged().Add(s.weaponPositionChanged)
-	weapon.ActiveChanged().Add(s.weaponActiveChanged)
-	weapon.OwnerChanged().Add(s.weaponOwnerChanged)
+	s.attach(weapon,
+		handle(weapon.PositionChanged(), s.weaponPositionChanged),
+		handle(weapon.ActiveChanged(), s.weaponActiveChanged),
+		handle(weapon.OwnerChanged(), s.weaponOwnerChanged),
+		handle(weapon.HandoverChanged(), s.weaponHandoverChanged),
+		handle(weapon.AmmunitionChanged(), s.weaponAmmunitionChanged),
+		handle(weapon.SectorChanged(), s.weaponSectorChanged),
+	)
+
+	weapon.setSolutions(s.solutions(weapon))
 
 	s.weapons[id] = weapon
 
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
 		WeaponAdded: weapon,
 	})
 
+	s.checkDangerClose()
+
 	return weapon, nil
 }
 
 func (s *session) weaponPositionChanged(sender Weapon, args PositionChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	sender.setSolutions(s.solutions(sender))
+	s.refreshPredictedImpact(sender)
+
+	s.publish(SessionChange{
 		WeaponChanged: sender,
 	})
+
+	s.checkDangerClose()
 }
 
 func (s *session) weaponActiveChanged(sender Weapon, args ActiveChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	s.publish(SessionChange{
 		WeaponChanged: sender,
 	})
+
+	s.checkDangerClose()
 }
 
 func (s *session) weaponOwnerChanged(sender Weapon, args OwnerChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
 		WeaponChanged: sender,
 	})
 }
 
+func (s *session) weaponHandoverChanged(sender Weapon, args HandoverChangedEventArgs) {
+	s.publish(SessionChange{
+		WeaponChanged: sender,
+	})
+}
+
+func (s *session) weaponAmmunitionChanged(sender Weapon, args AmmunitionChangedEventArgs) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	sender.setSolutions(s.solutions(sender))
+	s.refreshPredictedImpact(sender)
+
+	s.publish(SessionChange{
+		WeaponChanged: sender,
+	})
+
+	s.checkDangerClose()
+}
+
+func (s *session) weaponSectorChanged(sender Weapon, args SectorChangedEventArgs) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	sender.setSolutions(s.solutions(sender))
+	s.refreshPredictedImpact(sender)
+
+	s.publish(SessionChange{
+		WeaponChanged: sender,
+	})
+
+	s.checkDangerClose()
+}
+
 func (s *session) AddTarget() (Target, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -134,36 +360,116 @@ func (s *session) AddTarget() (Target, error) {
 		return nil, errors.New("maximum targets per sessions reached")
 	}
 
-	id := s.nextTargetId()
-	target := newTarget(id)
+	target := s.addTarget(newTarget(s.nextTargetId(), 0))
+
+	s.refreshSolutions()
+
+	return target, nil
+}
+
+// AddChildTargets adds a target at every position as a child of the parent target. The child
+// targets copy the active state of the parent when they are added, later changes of the parent
+// are not passed on to them. They are removed together with the parent.
+func (s *session) AddChildTargets(parentId TargetId, positions []math.Vector3) ([]Target, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	parent, ok := s.targets[parentId]
+	if !ok {
+		return nil, errors.New("target not found")
+	}
+
+	if len(s.targets)+len(positions) > s.maxTargets {
+		return nil, errors.New("maximum targets per sessions reached")
+	}
+
+	targets := make([]Target, 0, len(positions))
+	for _, position := range positions {
+		child := newTarget(s.nextTargetId(), parentId).(*target)
+		child.position = position
+		child.active = parent.Active()
 
-	target.PositionChanged().Add(s.targetPositionChanged)
-	target.ActiveChanged().Add(s.targetActiveChanged)
-	target.OwnerChanged().Add(s.targetOwnerChanged)
+		targets = append(targets, s.addTarget(child))
+	}
 
-	s.targets[id] = target
+	s.refreshSolutions()
 
-	s.updateSubject.Publish(SessionChange{
+	return targets, nil
+}
+
+// handle adds the delegate to the event and returns a function removing it again.
+func handle[S any, E any](event eventhandler.Event[S, E], delegate eventhandler.Delegate[S, E]) func() {
+	token := event.Add(delegate)
+
+	return func() {
+		event.Remove(token)
+	}
+}
+
+// attach keeps the functions removing the event handlers of the session from the entity. The
+// caller must hold the session lock.
+func (s *session) attach(entity any, removers ...func()) {
+	s.handlers[entity] = append(s.handlers[entity], removers...)
+}
+
+// detach removes all event handlers of the session from the entity. The caller must hold the
+// session lock.
+func (s *session) detach(entity any) {
+	for _, remove := range s.handlers[entity] {
+		remove()
+	}
+
+	delete(s.handlers, entity)
+}
+
+// addTarget registers the target and publishes it. The caller must hold the session lock.
+func (s *session) addTarget(target Target) Target {
+	s.attach(target,
+		handle(target.PositionChanged(), s.targetPositionChanged),
+		handle(target.ActiveChanged(), s.targetActiveChanged),
+		handle(target.OwnerChanged(), s.targetOwnerChanged),
+		handle(target.HandoverChanged(), s.targetHandoverChanged),
+	)
+
+	s.targets[target.Id()] = target
+
+	s.publish(SessionChange{
 		TargetAdded: target,
 	})
 
-	return target, nil
+	return target
 }
 
 func (s *session) targetPositionChanged(sender Target, args PositionChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	s.publish(SessionChange{
 		TargetChanged: sender,
 	})
+
+	s.refreshSolutions()
 }
 
 func (s *session) targetActiveChanged(sender Target, args ActiveChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	s.publish(SessionChange{
 		TargetChanged: sender,
 	})
+
+	s.refreshSolutions()
 }
 
 func (s *session) targetOwnerChanged(sender Target, args OwnerChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
+		TargetChanged: sender,
+	})
+}
+
+func (s *session) targetHandoverChanged(sender Target, args HandoverChangedEventArgs) {
+	s.publish(SessionChange{
 		TargetChanged: sender,
 	})
 }
@@ -179,14 +485,32 @@ func (s *session) RemoveWeapon(id WeaponId) (Weapon, error) {
 
 	delete(s.weapons, id)
 
-	weapon.PositionChanged().Remove(s.weaponPositionChanged)
-	weapon.ActiveChanged().Remove(s.weaponActiveChanged)
-	weapon.OwnerChanged().Remove(s.weaponOwnerChanged)
+	s.detach(weapon)
 
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
 		WeaponRemoved: weapon,
 	})
 
+	s.checkDangerClose()
+
+	for _, fireMission := range s.fireMissions {
+		if fireMission.removeWeapon(id) {
+			s.publish(SessionChange{
+				FireMissionChanged: fireMission,
+			})
+		}
+	}
+
+	s.cancelTimeOnTargets(func(timeOnTarget TimeOnTarget) bool {
+		for _, shot := range timeOnTarget.Shots() {
+			if shot.Weapon.Id() == id {
+				return true
+			}
+		}
+
+		return false
+	})
+
 	return weapon, nil
 }
 
@@ -199,23 +523,168 @@ func (s *session) RemoveTarget(id TargetId) (Target, error) {
 		return nil, errors.New("target is already removed")
 	}
 
+	for _, child := range s.targets {
+		if parentId, ok := child.ParentId(); ok && parentId == id {
+			s.removeTarget(child)
+		}
+	}
+
+	s.removeTarget(target)
+
+	s.refreshSolutions()
+
+	return target, nil
+}
+
+// removeTarget unregisters the target and removes it from all fire missions and times on target.
+// The caller must hold the session lock.
+func (s *session) removeTarget(target Target) {
+	id := target.Id()
+
 	delete(s.targets, id)
 
-	target.PositionChanged().Remove(s.targetPositionChanged)
-	target.ActiveChanged().Remove(s.targetActiveChanged)
-	target.OwnerChanged().Remove(s.targetOwnerChanged)
+	s.detach(target)
 
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
 		TargetRemoved: target,
 	})
 
-	return target, nil
+	for _, fireMission := range s.fireMissions {
+		if fireMission.removeTarget(id) {
+			s.publish(SessionChange{
+				FireMissionChanged: fireMission,
+			})
+		}
+	}
+
+	s.cancelTimeOnTargets(func(timeOnTarget TimeOnTarget) bool {
+		return timeOnTarget.Target().Id() == id
+	})
+}
+
+// solutions computes the firing solutions of a weapon to every active target. The caller must hold the session lock.
+func (s *session) solutions(weapon Weapon) []TargetSolution {
+	position := weapon.Position()
+
+	solutions := make([]TargetSolution, 0, len(s.targets))
+	for _, target := range s.targets {
+		if !target.Active() {
+			continue
+		}
+
+		solution := TargetSolution{
+			TargetId: target.Id(),
+			Solution: weapon.Solve(target.Position()),
+		}
+
+		if clearance, ok := terrain.CheckClearance(s.heightmap, position, solution.Solution); ok {
+			solution.Clearance = &clearance
+		}
+
+		solutions = append(solutions, solution)
+	}
+
+	sort.Slice(solutions, func(i, j int) bool {
+		return solutions[i].TargetId < solutions[j].TargetId
+	})
+
+	return solutions
+}
+
+// refreshSolutions recomputes the firing solutions of all weapons and checks for danger close
+// friendlies. The caller must hold the session lock.
+func (s *session) refreshSolutions() {
+	weapons := make([]Weapon, 0, len(s.weapons))
+	for _, weapon := range s.weapons {
+		weapon.setSolutions(s.solutions(weapon))
+		weapons = append(weapons, weapon)
+	}
+
+	if len(weapons) > 0 {
+		sort.Slice(weapons, func(i, j int) bool {
+			return weapons[i].Id() < weapons[j].Id()
+		})
+
+		s.publish(SessionChange{
+			WeaponSolutionsChanged: weapons,
+		})
+	}
+
+	s.checkDangerClose()
+}
+
+// PredictImpact places a predicted impact marker of firing the weapon with the elevation and azimuth in radians.
+func (s *session) PredictImpact(id WeaponId, elevation float64, azimuth float64) (Weapon, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	weapon, ok := s.weapons[id]
+	if !ok {
+		return nil, errors.New("weapon not found")
+	}
+
+	solution, err := weapon.Aim(elevation, azimuth)
+	if err != nil {
+		return nil, err
+	}
+
+	prediction := terrain.PredictImpact(s.heightmap, weapon.Position(), solution)
+	weapon.setPredictedImpact(&prediction)
+
+	s.publish(SessionChange{
+		WeaponChanged: weapon,
+	})
+
+	return weapon, nil
+}
+
+func (s *session) ClearPredictedImpact(id WeaponId) (Weapon, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	weapon, ok := s.weapons[id]
+	if !ok {
+		return nil, errors.New("weapon not found")
+	}
+
+	weapon.setPredictedImpact(nil)
+
+	s.publish(SessionChange{
+		WeaponChanged: weapon,
+	})
+
+	return weapon, nil
+}
+
+// refreshPredictedImpact recomputes the predicted impact of a weapon with the same aim, e.g. after it
+// has been moved. The caller must hold the session lock.
+func (s *session) refreshPredictedImpact(weapon Weapon) {
+	predicted := weapon.PredictedImpact()
+	if predicted == nil {
+		return
+	}
+
+	// The weapon is aimed again, as the ballistics change with the ammunition and sector.
+	solution, err := weapon.Aim(predicted.Solution.Elevation, predicted.Solution.Azimuth)
+	if err != nil {
+		weapon.setPredictedImpact(nil)
+		return
+	}
+
+	prediction := terrain.PredictImpact(s.heightmap, weapon.Position(), solution)
+	weapon.setPredictedImpact(&prediction)
 }
 
 func (s *session) Subscribe() pubsub.Subscription[SessionChange] {
+	s.Touch()
+
 	return s.updateSubject.Subscribe()
 }
 
+func (s *session) Subscribers() int {
+	return s.updateSubject.Subscribers()
+}
+
 func (s *session) MaxUsers() int {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
@@ -237,6 +706,20 @@ func (s *session) MaxTargets() int {
 	return s.maxTargets
 }
 
+func (s *session) MaxFireMissions() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.maxFireMissions
+}
+
+func (s *session) MaxTimeOnTargets() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.maxTimeOnTargets
+}
+
 func (s *session) SetMaxUsers(v int) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -258,6 +741,20 @@ func (s *session) SetMaxTargets(v int) {
 	s.maxTargets = v
 }
 
+func (s *session) SetMaxFireMissions(v int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.maxFireMissions = v
+}
+
+func (s *session) SetMaxTimeOnTargets(v int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.maxTimeOnTargets = v
+}
+
 func (s *session) Users() []User {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
@@ -324,7 +821,7 @@ func (s *session) Join(clientUuid uuid.UUID) (User, error) {
 		return nil, err
 	}
 
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
 		UserJoined: user,
 	})
 
@@ -336,20 +833,88 @@ func (s *session) join(clientUuid uuid.UUID) (User, error) {
 		return nil, errors.New("client already joined")
 	}
 
-	user := newUser(clientUuid, "")
-	user.NameChanged().Add(s.userNameChanged)
+	if _, ok := s.banned[clientUuid.String()]; ok {
+		return nil, errors.New("client is banned from session")
+	}
+
+	if s.hostUuid == uuid.Nil {
+		s.hostUuid = clientUuid
+	}
+
+	role := s.defaultRole
+	if clientUuid == s.hostUuid {
+		role = CommanderRole
+	}
+
+	user := newUser(clientUuid, "", time.Now(), role)
+	s.attach(user,
+		handle(user.NameChanged(), s.userNameChanged),
+		handle(user.RoleChanged(), s.userRoleChanged),
+	)
 
 	s.users[clientUuid.String()] = user
+	s.emptySince = time.Time{}
 
 	return user, nil
 }
 
 func (s *session) userNameChanged(sender User, args NameChangedEventArgs) {
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
+		UserChanged: sender,
+	})
+}
+
+func (s *session) userRoleChanged(sender User, args RoleChangedEventArgs) {
+	s.publish(SessionChange{
 		UserChanged: sender,
 	})
 }
 
+func (s *session) DefaultRole() Role {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.defaultRole
+}
+
+func (s *session) SetDefaultRole(role Role) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.defaultRole = role
+}
+
+// Map returns the map of the session, if one has been selected.
+func (s *session) Map() (gamemap.Map, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	if s.gameMap == nil {
+		return gamemap.Map{}, false
+	}
+
+	return *s.gameMap, true
+}
+
+// SetMap selects the map of the session. The heightmap may be nil if there is none for the map.
+func (s *session) SetMap(m gamemap.Map, heightmap terrain.Heightmap) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.gameMap = &m
+	s.heightmap = heightmap
+
+	s.publish(SessionChange{
+		MapChanged: &m,
+	})
+
+	for _, weapon := range s.weapons {
+		s.refreshPredictedImpact(weapon)
+	}
+
+	s.refreshSolutions()
+}
+
 func (s *session) Quit(clientUuid uuid.UUID) (User, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -359,11 +924,13 @@ func (s *session) Quit(clientUuid uuid.UUID) (User, error) {
 		return nil, err
 	}
 
-	s.updateSubject.Publish(SessionChange{
+	s.publish(SessionChange{
 		UserLeft: user,
 	})
 
-	return s.quit(clientUuid)
+	s.handOverHost(user)
+
+	return user, nil
 }
 
 func (s *session) quit(clientUuid uuid.UUID) (User, error) {
@@ -374,27 +941,75 @@ func (s *session) quit(clientUuid uuid.UUID) (User, error) {
 
 	delete(s.users, clientUuid.String())
 
-	user.NameChanged().Remove(s.userNameChanged)
+	if len(s.users) == 0 {
+		s.emptySince = time.Now()
+	}
+
+	s.detach(user)
+
+	// Targets and weapons are exclusively owned, so they would stay locked for everyone else.
+	for _, target := range s.targets {
+		target.leave(user)
+	}
+
+	for _, weapon := range s.weapons {
+		weapon.leave(user)
+	}
+
+	s.checkDangerClose()
 
 	return user, nil
 }
 
-func NewSession(uuid uuid.UUID, maxUsers int, maxWeapons int, maxTargets int) Session {
+func NewSession(uuid uuid.UUID, hostUuid uuid.UUID, maxUsers int, maxWeapons int, maxTargets int, maxFireMissions int, maxTimeOnTargets int) Session {
 	return &session{
 		uuid,
+		hostUuid,
 		maxUsers,
 		maxWeapons,
 		maxTargets,
+		maxFireMissions,
+		maxTimeOnTargets,
 
 		0,
 		0,
 
+		0,
+		0,
+		0,
+
 		make(map[string]User, 0),
 		make(map[WeaponId]Weapon, 0),
 		make(map[TargetId]Target, 0),
 
+		make(map[FireMissionId]FireMission, 0),
+		make(map[TimeOnTargetId]TimeOnTarget, 0),
+
+		make(map[EffectId]Effect, 0),
+		make(map[EffectId]*time.Timer, 0),
+
+		nil,
+
+		make(map[dangerCloseKey]DangerCloseWarning, 0),
+		sync.Mutex{},
+		false,
+
+		make(map[string]struct{}, 0),
+
+		make(map[any][]func(), 0),
+
+		SpectatorRole,
+
+		nil,
+		nil,
+
 		sync.RWMutex{},
 
+		time.Now(),
+		time.Now(),
+
+		sync.Mutex{},
+
 		pubsub.NewSubject[SessionChange](),
 	}
 }
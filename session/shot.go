@@ -0,0 +1,97 @@
+package session
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"time"
+)
+
+var ErrNoAimPoint = errors.New("weapon has neither a target nor a predicted impact")
+
+// Shot is a round fired by a weapon. The impact is projected from the time of flight at the time
+// it was fired, so that all clients can count down to the splash.
+type Shot struct {
+	Weapon     Weapon
+	Ammunition armory.Ammunition
+	FiredBy    User
+	Target     Target
+	Impact     ballistics.Impact
+	FiredAt    time.Time
+	ImpactAt   time.Time
+}
+
+// Fire fires the loaded ammunition of the weapon on behalf of the user. The round is fired on the
+// target if one is given, otherwise on the predicted impact of the weapon. If danger close fire is
+// blocked, it returns ErrDangerClose if a friendly is within the danger close radius of the impact.
+func (s *session) Fire(user User, weaponId WeaponId, targetId *TargetId) (Shot, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	weapon, ok := s.weapons[weaponId]
+	if !ok {
+		return Shot{}, errors.New("weapon not found")
+	}
+
+	shot := Shot{
+		Weapon:     weapon,
+		Ammunition: weapon.Ammunition(),
+		FiredBy:    user,
+		FiredAt:    time.Now(),
+	}
+
+	if targetId != nil {
+		target, ok := s.targets[*targetId]
+		if !ok {
+			return Shot{}, errors.New("target not found")
+		}
+
+		solution := weapon.Solve(target.Position())
+		if err := solutionError(solution); err != nil {
+			return Shot{}, err
+		}
+
+		shot.Target = target
+		shot.Impact = ballistics.Impact{
+			Position:           target.Position(),
+			HorizontalDistance: solution.HorizontalDistance,
+			TimeOfFlight:       solution.TimeOfFlight,
+		}
+	} else if prediction := weapon.PredictedImpact(); prediction != nil {
+		if err := solutionError(prediction.Solution); err != nil {
+			return Shot{}, err
+		}
+
+		shot.Impact = prediction.Impact
+	} else {
+		return Shot{}, ErrNoAimPoint
+	}
+
+	if err := s.checkFriendlies(shot.Impact.Position, dangerCloseRadius(weapon, shot.Impact.Position)); err != nil {
+		return Shot{}, err
+	}
+
+	shot.ImpactAt = shot.FiredAt.Add(seconds(shot.Impact.TimeOfFlight))
+
+	s.scheduleEffect(shot)
+
+	s.publish(SessionChange{
+		ShotFired: &shot,
+	})
+
+	return shot, nil
+}
+
+// dangerCloseRadius returns the danger close radius of the weapon firing on the impact. The blast
+// radius is used if the impact is out of range, e.g. because it is obstructed by the terrain.
+func dangerCloseRadius(weapon Weapon, impact math.Vector3) float64 {
+	profile := weapon.Profile()
+
+	solution := profile.Solve(weapon.Position(), impact)
+	if !solution.InRange() {
+		return profile.BlastRadius
+	}
+
+	return solution.Dispersion.DangerCloseRadius
+}
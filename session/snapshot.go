@@ -0,0 +1,315 @@
+package session
+
+import (
+	"github.com/google/uuid"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
+	"time"
+)
+
+// Snapshot is a serializable copy of the state of a session.
+type Snapshot struct {
+	Uuid     uuid.UUID `json:"uuid"`
+	HostUuid uuid.UUID `json:"hostUuid"`
+
+	MaxUsers   int `json:"maxUsers"`
+	MaxWeapons int `json:"maxWeapons"`
+	MaxTargets int `json:"maxTargets"`
+
+	MaxFireMissions  int `json:"maxFireMissions"`
+	MaxTimeOnTargets int `json:"maxTimeOnTargets"`
+
+	WeaponIdCounter WeaponId `json:"weaponIdCounter"`
+	TargetIdCounter TargetId `json:"targetIdCounter"`
+
+	FireMissionIdCounter FireMissionId `json:"fireMissionIdCounter"`
+
+	Users   []UserSnapshot   `json:"users"`
+	Weapons []WeaponSnapshot `json:"weapons"`
+	Targets []TargetSnapshot `json:"targets"`
+
+	FireMissions []FireMissionSnapshot `json:"fireMissions"`
+
+	Banned []uuid.UUID `json:"banned"`
+
+	DefaultRole Role `json:"defaultRole"`
+
+	BlockDangerCloseFire bool `json:"blockDangerCloseFire"`
+
+	Map *gamemap.Map `json:"map"`
+}
+
+type UserSnapshot struct {
+	ClientUuid uuid.UUID `json:"clientUuid"`
+	Name       string    `json:"name"`
+	JoinedAt   time.Time `json:"joinedAt"`
+	Role       Role      `json:"role"`
+}
+
+type WeaponSnapshot struct {
+	Id WeaponId `json:"id"`
+	// TypeId is resolved against the weapon registry on restore, so that changes to the registry
+	// apply to restored weapons.
+	TypeId     string        `json:"weaponTypeId"`
+	Ammunition string        `json:"ammunition"`
+	Position   math.Vector3  `json:"position"`
+	Active     bool          `json:"active"`
+	Owner      *UserSnapshot `json:"owner"`
+
+	// Heading and TraverseArc of the sector in radians.
+	Heading     float64 `json:"heading"`
+	TraverseArc float64 `json:"traverseArc"`
+
+	Lease          time.Duration `json:"lease"`
+	LeaseExpiresAt time.Time     `json:"leaseExpiresAt"`
+}
+
+type TargetSnapshot struct {
+	Id       TargetId      `json:"id"`
+	ParentId TargetId      `json:"parentId,omitempty"`
+	Position math.Vector3  `json:"position"`
+	Active   bool          `json:"active"`
+	Owner    *UserSnapshot `json:"owner"`
+
+	Lease          time.Duration `json:"lease"`
+	LeaseExpiresAt time.Time     `json:"leaseExpiresAt"`
+}
+
+type FireMissionSnapshot struct {
+	Id             FireMissionId    `json:"id"`
+	TargetIds      []TargetId       `json:"targetIds"`
+	WeaponIds      []WeaponId       `json:"weaponIds"`
+	Rounds         int              `json:"rounds"`
+	RequestedBy    *UserSnapshot    `json:"requestedBy"`
+	RequestedAt    time.Time        `json:"requestedAt"`
+	AcknowledgedBy *UserSnapshot    `json:"acknowledgedBy"`
+	State          FireMissionState `json:"state"`
+}
+
+func (s *session) Snapshot() Snapshot {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	snapshot := Snapshot{
+		Uuid:                 s.uuid,
+		HostUuid:             s.hostUuid,
+		MaxUsers:             s.maxUsers,
+		MaxWeapons:           s.maxWeapons,
+		MaxTargets:           s.maxTargets,
+		WeaponIdCounter:      s.weaponIdCounter,
+		TargetIdCounter:      s.targetIdCounter,
+		MaxFireMissions:      s.maxFireMissions,
+		MaxTimeOnTargets:     s.maxTimeOnTargets,
+		FireMissionIdCounter: s.fireMissionIdCounter,
+		Users:                make([]UserSnapshot, 0, len(s.users)),
+		Weapons:              make([]WeaponSnapshot, 0, len(s.weapons)),
+		Targets:              make([]TargetSnapshot, 0, len(s.targets)),
+		FireMissions:         make([]FireMissionSnapshot, 0, len(s.fireMissions)),
+		Banned:               make([]uuid.UUID, 0, len(s.banned)),
+		DefaultRole:          s.defaultRole,
+		BlockDangerCloseFire: s.blockDangerCloseFire,
+		Map:                  s.gameMap,
+	}
+
+	for _, user := range s.users {
+		snapshot.Users = append(snapshot.Users, *userSnapshot(user))
+	}
+
+	for _, w := range s.weapons {
+		owner, lease, leaseExpiresAt := w.(*weapon).ownership.snapshot()
+
+		snapshot.Weapons = append(snapshot.Weapons, WeaponSnapshot{
+			Id:             w.Id(),
+			TypeId:         w.Type().Id,
+			Ammunition:     w.Ammunition().Id,
+			Heading:        w.Sector().Heading,
+			TraverseArc:    w.Sector().Arc,
+			Position:       w.Position(),
+			Active:         w.Active(),
+			Owner:          userSnapshot(owner),
+			Lease:          lease,
+			LeaseExpiresAt: leaseExpiresAt,
+		})
+	}
+
+	for _, t := range s.targets {
+		owner, lease, leaseExpiresAt := t.(*target).ownership.snapshot()
+
+		parentId, _ := t.ParentId()
+
+		snapshot.Targets = append(snapshot.Targets, TargetSnapshot{
+			Id:             t.Id(),
+			ParentId:       parentId,
+			Position:       t.Position(),
+			Active:         t.Active(),
+			Owner:          userSnapshot(owner),
+			Lease:          lease,
+			LeaseExpiresAt: leaseExpiresAt,
+		})
+	}
+
+	for _, f := range s.fireMissions {
+		snapshot.FireMissions = append(snapshot.FireMissions, FireMissionSnapshot{
+			Id:             f.Id(),
+			TargetIds:      slice.Map(f.Targets(), Target.Id),
+			WeaponIds:      slice.Map(f.Weapons(), Weapon.Id),
+			Rounds:         f.Rounds(),
+			RequestedBy:    userSnapshot(f.RequestedBy()),
+			RequestedAt:    f.RequestedAt(),
+			AcknowledgedBy: userSnapshot(f.AcknowledgedBy()),
+			State:          f.State(),
+		})
+	}
+
+	for clientUuid := range s.banned {
+		snapshot.Banned = append(snapshot.Banned, uuid.MustParse(clientUuid))
+	}
+
+	return snapshot
+}
+
+func userSnapshot(user User) *UserSnapshot {
+	if user == nil {
+		return nil
+	}
+
+	return &UserSnapshot{
+		ClientUuid: user.ClientUuid(),
+		Name:       user.Name(),
+		JoinedAt:   user.JoinedAt(),
+		Role:       user.Role(),
+	}
+}
+
+// RestoreSession creates a session from a snapshot without publishing any changes. Weapons of types
+// which are no longer in the registry are dropped.
+func RestoreSession(snapshot Snapshot, heightmaps terrain.Store, weaponTypes armory.Registry) Session {
+	s := NewSession(snapshot.Uuid, snapshot.HostUuid, snapshot.MaxUsers, snapshot.MaxWeapons, snapshot.MaxTargets, snapshot.MaxFireMissions, snapshot.MaxTimeOnTargets).(*session)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.defaultRole = snapshot.DefaultRole
+	s.blockDangerCloseFire = snapshot.BlockDangerCloseFire
+	s.gameMap = snapshot.Map
+
+	if snapshot.Map != nil {
+		if heightmap, err := heightmaps.Heightmap(snapshot.Map.Id); err == nil {
+			s.heightmap = heightmap
+		}
+	}
+	s.weaponIdCounter = snapshot.WeaponIdCounter
+	s.targetIdCounter = snapshot.TargetIdCounter
+	s.fireMissionIdCounter = snapshot.FireMissionIdCounter
+
+	for _, u := range snapshot.Users {
+		user := newUser(u.ClientUuid, u.Name, u.JoinedAt, u.Role)
+		s.attach(user,
+			handle(user.NameChanged(), s.userNameChanged),
+			handle(user.RoleChanged(), s.userRoleChanged),
+		)
+
+		s.users[u.ClientUuid.String()] = user
+	}
+
+	if len(s.users) > 0 {
+		s.emptySince = time.Time{}
+	}
+
+	for _, clientUuid := range snapshot.Banned {
+		s.banned[clientUuid.String()] = struct{}{}
+	}
+
+	for _, t := range snapshot.Targets {
+		restored := newTarget(t.Id, t.ParentId).(*target)
+		restored.position = t.Position
+		restored.active = t.Active
+		restored.ownership.restore(s.restoreUser(t.Owner), t.Lease, t.LeaseExpiresAt, restored.expireLease)
+
+		s.attach(restored,
+			handle(restored.PositionChanged(), s.targetPositionChanged),
+			handle(restored.ActiveChanged(), s.targetActiveChanged),
+			handle(restored.OwnerChanged(), s.targetOwnerChanged),
+			handle(restored.HandoverChanged(), s.targetHandoverChanged),
+		)
+
+		s.targets[t.Id] = restored
+	}
+
+	for _, w := range snapshot.Weapons {
+		weaponType, err := weaponTypes.WeaponType(w.TypeId)
+		if err != nil {
+			continue
+		}
+
+		restored := newWeapon(w.Id, weaponType).(*weapon)
+		if ammunition, err := restored.typ.FindAmmunition(w.Ammunition); err == nil {
+			restored.ammunition = ammunition
+		}
+		// Sectors of snapshots taken before sectors were stored fall back to the default sector.
+		if sector, err := restored.typ.Sector(w.Heading, w.TraverseArc); err == nil {
+			restored.sector = sector
+		}
+
+		restored.position = w.Position
+		restored.active = w.Active
+		restored.ownership.restore(s.restoreUser(w.Owner), w.Lease, w.LeaseExpiresAt, restored.expireLease)
+
+		s.attach(restored,
+			handle(restored.PositionChanged(), s.weaponPositionChanged),
+			handle(restored.ActiveChanged(), s.weaponActiveChanged),
+			handle(restored.OwnerChanged(), s.weaponOwnerChanged),
+			handle(restored.HandoverChanged(), s.weaponHandoverChanged),
+			handle(restored.AmmunitionChanged(), s.weaponAmmunitionChanged),
+			handle(restored.SectorChanged(), s.weaponSectorChanged),
+		)
+
+		s.weapons[w.Id] = restored
+	}
+
+	for _, f := range snapshot.FireMissions {
+		targets := make([]Target, 0, len(f.TargetIds))
+		for _, id := range f.TargetIds {
+			if target, ok := s.targets[id]; ok {
+				targets = append(targets, target)
+			}
+		}
+
+		weapons := make([]Weapon, 0, len(f.WeaponIds))
+		for _, id := range f.WeaponIds {
+			if weapon, ok := s.weapons[id]; ok {
+				weapons = append(weapons, weapon)
+			}
+		}
+
+		restored := newFireMission(f.Id, targets, weapons, f.Rounds, s.restoreUser(f.RequestedBy), f.RequestedAt).(*fireMission)
+		restored.acknowledgedBy = s.restoreUser(f.AcknowledgedBy)
+		restored.state = f.State
+
+		s.attach(restored, handle(restored.StateChanged(), s.fireMissionStateChanged))
+
+		s.fireMissions[f.Id] = restored
+	}
+
+	for _, weapon := range s.weapons {
+		weapon.setSolutions(s.solutions(weapon))
+	}
+
+	return s
+}
+
+// restoreUser returns the joined user of a snapshot or a detached user if the user already quit.
+func (s *session) restoreUser(snapshot *UserSnapshot) User {
+	if snapshot == nil {
+		return nil
+	}
+
+	if user, ok := s.users[snapshot.ClientUuid.String()]; ok {
+		return user
+	}
+
+	return newUser(snapshot.ClientUuid, snapshot.Name, snapshot.JoinedAt, snapshot.Role)
+}
@@ -0,0 +1,29 @@
+package session
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
+)
+
+// TargetSolution is the firing solution of a weapon to a target. Clearance is only set if the
+// session has a heightmap and the target is in range.
+type TargetSolution struct {
+	TargetId  TargetId
+	Solution  ballistics.Solution
+	Clearance *terrain.Clearance
+}
+
+var ErrOutOfArc = errors.New("aim point is outside of the sector of the weapon")
+
+// solutionError returns why the solution cannot be fired or nil if it is in range.
+func solutionError(solution ballistics.Solution) error {
+	switch solution.Status {
+	case ballistics.InRange:
+		return nil
+	case ballistics.OutOfArc:
+		return ErrOutOfArc
+	default:
+		return ErrTargetOutOfRange
+	}
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var sessionsBucket = []byte("sessions")
+
+// PersistentStorage is a Storage which keeps its sessions across restarts.
+type PersistentStorage interface {
+	Storage
+
+	// Snapshot writes the current state of all sessions to disk.
+	Snapshot() error
+	// Close writes a final snapshot and releases the underlying store.
+	Close() error
+}
+
+// boltStorage keeps all sessions in memory and periodically snapshots them into a bbolt database.
+type boltStorage struct {
+	*storage
+
+	db       *bbolt.DB
+	interval time.Duration
+	logger   *zap.Logger
+
+	heightmaps  terrain.Store
+	weaponTypes armory.Registry
+
+	stop chan struct{}
+	done chan struct{}
+
+	snapshotMtx sync.Mutex
+}
+
+func (s *boltStorage) Delete(uuid uuid.UUID) error {
+	if err := s.storage.Delete(uuid); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(uuid.String()))
+	})
+}
+
+func (s *boltStorage) Snapshot() error {
+	s.snapshotMtx.Lock()
+	defer s.snapshotMtx.Unlock()
+
+	sessions := s.storage.Sessions()
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket(sessionsBucket); err != nil {
+			return err
+		}
+
+		bucket, err := tx.CreateBucket(sessionsBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, sess := range sessions {
+			bs, err := json.Marshal(sess.Snapshot())
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.Put([]byte(sess.Uuid().String()), bs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *boltStorage) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+
+	if err := s.Snapshot(); err != nil {
+		s.db.Close()
+		return err
+	}
+
+	return s.db.Close()
+}
+
+func (s *boltStorage) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Snapshot(); err != nil {
+				s.logger.Error("could not snapshot sessions", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *boltStorage) restore() error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(sessionsBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var snapshot session.Snapshot
+			if err := json.Unmarshal(v, &snapshot); err != nil {
+				s.logger.Warn("skipping invalid session snapshot", zap.ByteString("session", k), zap.Error(err))
+				return nil
+			}
+
+			s.storage.sessions[snapshot.Uuid.String()] = session.RestoreSession(snapshot, s.heightmaps, s.weaponTypes)
+
+			return nil
+		})
+	})
+}
+
+// NewBoltStorage opens the bbolt database at path, restores all sessions stored in it and
+// snapshots them every interval. A zero interval only snapshots on Close.
+func NewBoltStorage(path string, interval time.Duration, heightmaps terrain.Store, weaponTypes armory.Registry, logger *zap.Logger) (PersistentStorage, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &boltStorage{
+		storage:     newStorage(),
+		db:          db,
+		interval:    interval,
+		logger:      logger,
+		heightmaps:  heightmaps,
+		weaponTypes: weaponTypes,
+		stop:        nil,
+		done:        nil,
+		snapshotMtx: sync.Mutex{},
+	}
+
+	if err := s.restore(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logger.Info("restored sessions", zap.String("path", path), zap.Int("count", len(s.storage.sessions)))
+
+	if interval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+
+		go s.run()
+	}
+
+	return s, nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Janitor periodically removes sessions from a Storage which have been idle for longer than
+// the idle TTL or which have been empty for longer than the empty grace period. Sessions with
+// subscribers are never idle. A zero duration disables the respective check, a zero interval
+// disables the janitor.
+type Janitor interface {
+	Start()
+	Stop()
+}
+
+type janitor struct {
+	storage          Storage
+	interval         time.Duration
+	idleTtl          time.Duration
+	emptyGracePeriod time.Duration
+	logger           *zap.Logger
+
+	stop chan struct{}
+
+	mtx sync.Mutex
+}
+
+func (j *janitor) Start() {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.stop != nil || j.interval <= 0 {
+		return
+	}
+
+	j.stop = make(chan struct{})
+
+	go j.run(j.stop)
+}
+
+func (j *janitor) Stop() {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.stop == nil {
+		return
+	}
+
+	close(j.stop)
+	j.stop = nil
+}
+
+func (j *janitor) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			j.sweep(now)
+		}
+	}
+}
+
+func (j *janitor) sweep(now time.Time) {
+	for _, s := range j.storage.Sessions() {
+		reason := ""
+
+		// Subscribed users follow the session passively, so the idle TTL starts once the last one left.
+		if s.Subscribers() > 0 {
+			s.Touch()
+		}
+
+		if j.idleTtl > 0 && now.Sub(s.LastActivity()) > j.idleTtl {
+			reason = "idle"
+		}
+
+		if emptySince, empty := s.EmptySince(); j.emptyGracePeriod > 0 && empty && now.Sub(emptySince) > j.emptyGracePeriod {
+			reason = "empty"
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		s.Close()
+
+		if err := j.storage.Delete(s.Uuid()); err != nil {
+			j.logger.Warn("could not delete session", zap.String("session", s.Uuid().String()), zap.Error(err))
+			continue
+		}
+
+		j.logger.Info("deleted session", zap.String("session", s.Uuid().String()), zap.String("reason", reason))
+	}
+}
+
+func NewJanitor(storage Storage, interval time.Duration, idleTtl time.Duration, emptyGracePeriod time.Duration, logger *zap.Logger) Janitor {
+	return &janitor{
+		storage:          storage,
+		interval:         interval,
+		idleTtl:          idleTtl,
+		emptyGracePeriod: emptyGracePeriod,
+		logger:           logger,
+		stop:             nil,
+		mtx:              sync.Mutex{},
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/session"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
 	"sync"
 )
 
 type Storage interface {
-	Create() (session.Session, error)
+	Create(hostUuid uuid.UUID) (session.Session, error)
 	Delete(uuid uuid.UUID) error
 	Get(uuid uuid.UUID) (session.Session, error)
+	Sessions() []session.Session
 }
 
 type storage struct {
@@ -19,7 +21,7 @@ type storage struct {
 	mtx sync.Mutex
 }
 
-func (s *storage) Create() (session.Session, error) {
+func (s *storage) Create(hostUuid uuid.UUID) (session.Session, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
@@ -30,9 +32,12 @@ func (s *storage) Create() (session.Session, error) {
 
 	session := session.NewSession(
 		uuid,
+		hostUuid,
 		30,
 		200,
 		200,
+		100,
+		10,
 	)
 	s.sessions[uuid.String()] = session
 
@@ -51,6 +56,13 @@ func (s *storage) Get(uuid uuid.UUID) (session.Session, error) {
 	return session, nil
 }
 
+func (s *storage) Sessions() []session.Session {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return slice.MapValuesToSlice(s.sessions)
+}
+
 func (s *storage) Delete(uuid uuid.UUID) error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -66,6 +78,10 @@ func (s *storage) Delete(uuid uuid.UUID) error {
 }
 
 func NewStorage() Storage {
+	return newStorage()
+}
+
+func newStorage() *storage {
 	return &storage{
 		make(map[string]session.Session, 0),
 		sync.Mutex{},
@@ -4,12 +4,15 @@ import (
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/eventhandler"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
 	"sync"
+	"time"
 )
 
 type TargetId int32
 
 type Target interface {
 	Id() TargetId
+	// ParentId returns the target the target has been derived from, e.g. by a fire pattern.
+	ParentId() (TargetId, bool)
 	Position() math.Vector3
 	SetPosition(v math.Vector3)
 	AddPosition(v math.Vector3)
@@ -21,6 +24,15 @@ type Target interface {
 	SetOwner(User)
 	OwnerChanged() eventhandler.Event[Target, OwnerChangedEventArgs]
 	IsOwned() bool
+	Acquire(user User, lease time.Duration) error
+	Release(user User) error
+	LeaseExpiresAt() (time.Time, bool)
+	HandoverRequester() User
+	RequestHandover(user User) error
+	RespondHandover(user User, accept bool) error
+	HandoverChanged() eventhandler.Event[Target, HandoverChangedEventArgs]
+
+	leave(user User)
 }
 
 type PositionChangedEventArgs struct {
@@ -40,13 +52,16 @@ type OwnerChangedEventArgs struct {
 
 type target struct {
 	id       TargetId
+	parentId TargetId
 	position math.Vector3
 	active   bool
-	owner    User
+
+	ownership ownership
 
 	positionEventHandler eventhandler.EventHandler[Target, PositionChangedEventArgs]
 	activeEventHandler   eventhandler.EventHandler[Target, ActiveChangedEventArgs]
 	ownerEventHandler    eventhandler.EventHandler[Target, OwnerChangedEventArgs]
+	handoverEventHandler eventhandler.EventHandler[Target, HandoverChangedEventArgs]
 
 	mtx sync.RWMutex
 }
@@ -55,6 +70,10 @@ func (t *target) Id() TargetId {
 	return t.id
 }
 
+func (t *target) ParentId() (TargetId, bool) {
+	return t.parentId, t.parentId != 0
+}
+
 func (t *target) Position() math.Vector3 {
 	t.mtx.RLock()
 	defer t.mtx.RUnlock()
@@ -112,31 +131,128 @@ func (t *target) SetActive(v bool) {
 }
 
 func (t *target) Owner() User {
-	t.mtx.RLock()
-	defer t.mtx.RUnlock()
-
-	return t.owner
+	return t.ownership.Owner()
 }
 
+// SetOwner replaces the owner regardless of the current owner or lease.
 func (t *target) SetOwner(u User) {
-	t.mtx.Lock()
+	old := t.ownership.force(u)
 
-	old := t.owner
-	t.owner = u
+	t.ownerEventHandler.Invoke(t, OwnerChangedEventArgs{
+		OldUser: old,
+		NewUser: u,
+	})
+}
 
-	t.mtx.Unlock()
+func (t *target) IsOwned() bool {
+	return t.ownership.Owner() != nil
+}
+
+// Acquire makes the user the owner for the lease duration or renews the lease if the user
+// already owns it. It fails with ErrAlreadyOwned if another user owns it. A zero lease never expires.
+func (t *target) Acquire(u User, lease time.Duration) error {
+	old, err := t.ownership.acquire(u, lease, t.expireLease)
+	if err != nil {
+		return err
+	}
 
 	t.ownerEventHandler.Invoke(t, OwnerChangedEventArgs{
 		OldUser: old,
 		NewUser: u,
 	})
+
+	return nil
 }
 
-func (t *target) IsOwned() bool {
-	t.mtx.RLock()
-	defer t.mtx.RUnlock()
+func (t *target) Release(u User) error {
+	old, err := t.ownership.release(u)
+	if err != nil {
+		return err
+	}
+
+	t.ownerEventHandler.Invoke(t, OwnerChangedEventArgs{
+		OldUser: old,
+		NewUser: nil,
+	})
+
+	return nil
+}
+
+func (t *target) expireLease(generation uint64) {
+	old, ok := t.ownership.expire(generation)
+	if !ok {
+		return
+	}
 
-	return t.owner != nil
+	t.ownerEventHandler.Invoke(t, OwnerChangedEventArgs{
+		OldUser: old,
+		NewUser: nil,
+	})
+}
+
+// leave releases the target if the user owns it and dismisses a handover requested by the user.
+func (t *target) leave(u User) {
+	old, dismissed := t.ownership.leave(u)
+
+	if old != nil {
+		t.ownerEventHandler.Invoke(t, OwnerChangedEventArgs{
+			OldUser: old,
+			NewUser: nil,
+		})
+	}
+
+	if dismissed {
+		t.handoverEventHandler.Invoke(t, HandoverChangedEventArgs{
+			OldRequester: u,
+			NewRequester: nil,
+		})
+	}
+}
+
+func (t *target) LeaseExpiresAt() (time.Time, bool) {
+	return t.ownership.LeaseExpiresAt()
+}
+
+func (t *target) HandoverRequester() User {
+	return t.ownership.HandoverRequester()
+}
+
+func (t *target) RequestHandover(u User) error {
+	old, err := t.ownership.requestHandover(u)
+	if err != nil {
+		return err
+	}
+
+	t.handoverEventHandler.Invoke(t, HandoverChangedEventArgs{
+		OldRequester: old,
+		NewRequester: u,
+	})
+
+	return nil
+}
+
+// RespondHandover hands the ownership over to the requesting user if accepted, otherwise the request is dismissed.
+func (t *target) RespondHandover(u User, accept bool) error {
+	old, requester, err := t.ownership.respondHandover(u, accept, t.expireLease)
+	if err != nil {
+		return err
+	}
+
+	if accept {
+		t.ownerEventHandler.Invoke(t, OwnerChangedEventArgs{
+			OldUser: old,
+			NewUser: requester,
+		})
+
+		return nil
+	}
+
+	t.handoverEventHandler.Invoke(t, HandoverChangedEventArgs{
+		OldRequester: requester,
+		NewRequester: nil,
+	})
+
+	return nil
 }
 
 func (t *target) PositionChanged() eventhandler.Event[Target, PositionChangedEventArgs] {
@@ -151,15 +267,21 @@ func (t *target) OwnerChanged() eventhandler.Event[Target, OwnerChangedEventArgs
 	return t.ownerEventHandler
 }
 
-func newTarget(id TargetId) Target {
+func (t *target) HandoverChanged() eventhandler.Event[Target, HandoverChangedEventArgs] {
+	return t.handoverEventHandler
+}
+
+func newTarget(id TargetId, parentId TargetId) Target {
 	return &target{
 		id,
+		parentId,
 		math.Vector3{},
 		false,
-		nil,
+		ownership{},
 		eventhandler.New[Target, PositionChangedEventArgs](),
 		eventhandler.New[Target, ActiveChangedEventArgs](),
 		eventhandler.New[Target, OwnerChangedEventArgs](),
+		eventhandler.New[Target, HandoverChangedEventArgs](),
 		sync.RWMutex{},
 	}
 }
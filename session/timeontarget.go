@@ -0,0 +1,315 @@
+package session
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/slice"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrTargetOutOfRange = errors.New("target is out of range of a weapon")
+
+// maxTimeOnTargetDelay is the longest time the first shot of a time on target may be scheduled ahead.
+const maxTimeOnTargetDelay = 5 * time.Minute
+
+type TimeOnTargetId int32
+
+type TimeOnTargetState int32
+
+const (
+	ScheduledTimeOnTargetState TimeOnTargetState = iota
+	CompleteTimeOnTargetState
+	CancelledTimeOnTargetState
+)
+
+// TimeOnTargetShot is the time a weapon has to fire at to impact together with all other weapons.
+type TimeOnTargetShot struct {
+	Weapon   Weapon
+	Solution ballistics.Solution
+	FireAt   time.Time
+}
+
+// TimeOnTargetCountdown is a tick of the countdown of a time on target. Ticks are sent every full
+// second before the impact and at the fire time of every shot.
+type TimeOnTargetCountdown struct {
+	TimeOnTarget TimeOnTarget
+	At           time.Time
+}
+
+// TimeOnTarget staggers the fire times of weapons with differing times of flight, so that all
+// rounds impact on the target at the same time. Times on target are not persisted.
+type TimeOnTarget interface {
+	Id() TimeOnTargetId
+	Target() Target
+	Shots() []TimeOnTargetShot
+	ImpactAt() time.Time
+	RequestedBy() User
+	State() TimeOnTargetState
+
+	ticks() []time.Time
+	finish(state TimeOnTargetState) bool
+	done() <-chan struct{}
+}
+
+type timeOnTarget struct {
+	id          TimeOnTargetId
+	target      Target
+	shots       []TimeOnTargetShot
+	impactAt    time.Time
+	requestedBy User
+	state       TimeOnTargetState
+
+	doneCh chan struct{}
+
+	mtx sync.RWMutex
+}
+
+func (t *timeOnTarget) Id() TimeOnTargetId {
+	return t.id
+}
+
+func (t *timeOnTarget) Target() Target {
+	return t.target
+}
+
+func (t *timeOnTarget) Shots() []TimeOnTargetShot {
+	return append([]TimeOnTargetShot(nil), t.shots...)
+}
+
+func (t *timeOnTarget) ImpactAt() time.Time {
+	return t.impactAt
+}
+
+func (t *timeOnTarget) RequestedBy() User {
+	return t.requestedBy
+}
+
+func (t *timeOnTarget) State() TimeOnTargetState {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	return t.state
+}
+
+// ticks returns the instants of the countdown in chronological order, ending with the impact.
+func (t *timeOnTarget) ticks() []time.Time {
+	first := t.impactAt
+	for _, shot := range t.shots {
+		if shot.FireAt.Before(first) {
+			first = shot.FireAt
+		}
+	}
+
+	ticks := make([]time.Time, 0)
+	for at := t.impactAt; !at.Before(first); at = at.Add(-time.Second) {
+		ticks = append(ticks, at)
+	}
+
+	for _, shot := range t.shots {
+		ticks = append(ticks, shot.FireAt)
+	}
+
+	sort.Slice(ticks, func(i, j int) bool {
+		return ticks[i].Before(ticks[j])
+	})
+
+	return ticks
+}
+
+// finish ends a scheduled time on target with the state. It returns false if it has already ended.
+func (t *timeOnTarget) finish(state TimeOnTargetState) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.state != ScheduledTimeOnTargetState {
+		return false
+	}
+
+	t.state = state
+	close(t.doneCh)
+
+	return true
+}
+
+func (t *timeOnTarget) done() <-chan struct{} {
+	return t.doneCh
+}
+
+func newTimeOnTarget(id TimeOnTargetId, target Target, shots []TimeOnTargetShot, impactAt time.Time, requestedBy User) TimeOnTarget {
+	return &timeOnTarget{
+		id,
+		target,
+		shots,
+		impactAt,
+		requestedBy,
+		ScheduledTimeOnTargetState,
+		make(chan struct{}),
+		sync.RWMutex{},
+	}
+}
+
+func (s *session) nextTimeOnTargetId() TimeOnTargetId {
+	s.timeOnTargetIdCounter++
+	return s.timeOnTargetIdCounter
+}
+
+func (s *session) TimeOnTargets() []TimeOnTarget {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	timeOnTargets := slice.MapValuesToSlice(s.timeOnTargets)
+	sort.Slice(timeOnTargets, func(i, j int) bool {
+		return timeOnTargets[i].Id() < timeOnTargets[j].Id()
+	})
+
+	return timeOnTargets
+}
+
+func (s *session) TimeOnTarget(id TimeOnTargetId) (TimeOnTarget, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	timeOnTarget, ok := s.timeOnTargets[id]
+	if !ok {
+		return nil, errors.New("time on target not found")
+	}
+
+	return timeOnTarget, nil
+}
+
+// ScheduleTimeOnTarget schedules the weapons to fire on the target, so that all rounds impact at
+// the same time. The weapon with the longest time of flight fires after the delay.
+func (s *session) ScheduleTimeOnTarget(user User, targetId TargetId, weaponIds []WeaponId, delay time.Duration) (TimeOnTarget, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(s.timeOnTargets) >= s.maxTimeOnTargets {
+		return nil, errors.New("maximum times on target per session reached")
+	}
+
+	if len(weaponIds) == 0 {
+		return nil, errors.New("time on target needs at least one weapon")
+	}
+
+	if delay < 0 || delay > maxTimeOnTargetDelay {
+		return nil, errors.New("time on target delay must be between 0 and 5 minutes")
+	}
+
+	target, ok := s.targets[targetId]
+	if !ok {
+		return nil, errors.New("target not found")
+	}
+
+	shots := make([]TimeOnTargetShot, 0, len(weaponIds))
+	longest := 0.0
+	for _, id := range weaponIds {
+		weapon, ok := s.weapons[id]
+		if !ok {
+			return nil, errors.New("weapon not found")
+		}
+
+		for _, shot := range shots {
+			if shot.Weapon.Id() == id {
+				return nil, errors.New("weapon is given more than once")
+			}
+		}
+
+		solution := weapon.Solve(target.Position())
+		if err := solutionError(solution); err != nil {
+			return nil, err
+		}
+
+		if solution.TimeOfFlight > longest {
+			longest = solution.TimeOfFlight
+		}
+
+		shots = append(shots, TimeOnTargetShot{
+			Weapon:   weapon,
+			Solution: solution,
+		})
+	}
+
+	impactAt := time.Now().Add(delay + seconds(longest))
+	for i := range shots {
+		shots[i].FireAt = impactAt.Add(-seconds(shots[i].Solution.TimeOfFlight))
+	}
+
+	timeOnTarget := newTimeOnTarget(s.nextTimeOnTargetId(), target, shots, impactAt, user)
+	s.timeOnTargets[timeOnTarget.Id()] = timeOnTarget
+
+	s.publish(SessionChange{
+		TimeOnTargetScheduled: timeOnTarget,
+	})
+
+	go s.countdown(timeOnTarget)
+
+	return timeOnTarget, nil
+}
+
+func (s *session) CancelTimeOnTarget(id TimeOnTargetId) (TimeOnTarget, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	timeOnTarget, ok := s.timeOnTargets[id]
+	if !ok {
+		return nil, errors.New("time on target has already ended")
+	}
+
+	s.endTimeOnTarget(timeOnTarget, CancelledTimeOnTargetState)
+
+	return timeOnTarget, nil
+}
+
+// endTimeOnTarget removes the time on target and publishes its end. The caller must hold the session lock.
+func (s *session) endTimeOnTarget(timeOnTarget TimeOnTarget, state TimeOnTargetState) {
+	if !timeOnTarget.finish(state) {
+		return
+	}
+
+	delete(s.timeOnTargets, timeOnTarget.Id())
+
+	s.publish(SessionChange{
+		TimeOnTargetEnded: timeOnTarget,
+	})
+}
+
+// cancelTimeOnTargets cancels all times on target matching the predicate. The caller must hold the session lock.
+func (s *session) cancelTimeOnTargets(predicate func(timeOnTarget TimeOnTarget) bool) {
+	for _, timeOnTarget := range s.timeOnTargets {
+		if predicate(timeOnTarget) {
+			s.endTimeOnTarget(timeOnTarget, CancelledTimeOnTargetState)
+		}
+	}
+}
+
+// countdown publishes the countdown of the time on target until the impact or until it is cancelled.
+func (s *session) countdown(timeOnTarget TimeOnTarget) {
+	for _, at := range timeOnTarget.ticks() {
+		timer := time.NewTimer(time.Until(at))
+
+		select {
+		case <-timer.C:
+		case <-timeOnTarget.done():
+			timer.Stop()
+			return
+		}
+
+		s.publish(SessionChange{
+			TimeOnTargetCountdown: &TimeOnTargetCountdown{
+				TimeOnTarget: timeOnTarget,
+				At:           at,
+			},
+		})
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.endTimeOnTarget(timeOnTarget, CompleteTimeOnTargetState)
+}
+
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
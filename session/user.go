@@ -3,15 +3,27 @@ package session
 import (
 	"github.com/google/uuid"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/eventhandler"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
 	"sync"
+	"time"
 )
 
 type User interface {
 	ClientUuid() uuid.UUID
 	Name() string
 	SetName(name string)
+	JoinedAt() time.Time
+	Role() Role
+	SetRole(role Role)
+	// Position returns the last reported in-game position of the user until it expires.
+	Position() (math.Vector3, bool)
+	PositionExpiresAt() (time.Time, bool)
 
 	NameChanged() eventhandler.Event[User, NameChangedEventArgs]
+	RoleChanged() eventhandler.Event[User, RoleChangedEventArgs]
+
+	setPosition(position math.Vector3, expiresAt time.Time) uint64
+	expirePosition(generation uint64) bool
 }
 
 type NameChangedEventArgs struct {
@@ -22,8 +34,17 @@ type NameChangedEventArgs struct {
 type user struct {
 	clientUuid uuid.UUID
 	name       string
+	joinedAt   time.Time
+	role       Role
+
+	// Every reported position increments the generation, so that timers of replaced positions can
+	// be detected and ignored.
+	position           math.Vector3
+	positionExpiresAt  time.Time
+	positionGeneration uint64
 
 	nameChangedEventHandler eventhandler.EventHandler[User, NameChangedEventArgs]
+	roleChangedEventHandler eventhandler.EventHandler[User, RoleChangedEventArgs]
 
 	mtx sync.RWMutex
 }
@@ -53,15 +74,96 @@ func (u *user) SetName(name string) {
 	})
 }
 
+func (u *user) JoinedAt() time.Time {
+	return u.joinedAt
+}
+
+func (u *user) Role() Role {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+
+	return u.role
+}
+
+func (u *user) SetRole(role Role) {
+	u.mtx.Lock()
+
+	old := u.role
+	u.role = role
+
+	u.mtx.Unlock()
+
+	u.roleChangedEventHandler.Invoke(u, RoleChangedEventArgs{
+		OldRole: old,
+		NewRole: role,
+	})
+}
+
+func (u *user) Position() (math.Vector3, bool) {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+
+	if u.positionExpiresAt.IsZero() || !time.Now().Before(u.positionExpiresAt) {
+		return math.Vector3{}, false
+	}
+
+	return u.position, true
+}
+
+func (u *user) PositionExpiresAt() (time.Time, bool) {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+
+	return u.positionExpiresAt, !u.positionExpiresAt.IsZero()
+}
+
+// setPosition replaces the reported position and returns its generation.
+func (u *user) setPosition(position math.Vector3, expiresAt time.Time) uint64 {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	u.position = position
+	u.positionExpiresAt = expiresAt
+	u.positionGeneration++
+
+	return u.positionGeneration
+}
+
+// expirePosition clears the reported position if the position of the given generation is still
+// the current one. It returns false otherwise.
+func (u *user) expirePosition(generation uint64) bool {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	if u.positionGeneration != generation || u.positionExpiresAt.IsZero() {
+		return false
+	}
+
+	u.position = math.Vector3{}
+	u.positionExpiresAt = time.Time{}
+
+	return true
+}
+
 func (u *user) NameChanged() eventhandler.Event[User, NameChangedEventArgs] {
 	return u.nameChangedEventHandler
 }
 
-func newUser(clientUuid uuid.UUID, name string) User {
+func (u *user) RoleChanged() eventhandler.Event[User, RoleChangedEventArgs] {
+	return u.roleChangedEventHandler
+}
+
+func newUser(clientUuid uuid.UUID, name string, joinedAt time.Time, role Role) User {
 	return &user{
 		clientUuid,
 		name,
+		joinedAt,
+		role,
+		math.Vector3{},
+		time.Time{},
+		0,
 		eventhandler.New[User, NameChangedEventArgs](),
+		eventhandler.New[User, RoleChangedEventArgs](),
 		sync.RWMutex{},
 	}
 }
@@ -1,25 +1,35 @@
 package session
 
 import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/armory"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/eventhandler"
 	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/terrain"
 	"sync"
+	"time"
 )
 
 type WeaponId int32
 
-type WeaponType int32
-
-const (
-	StandardMortarWeaponType WeaponType = iota
-	TechnicalMortarWeaponType
-	RocketsWeaponType
-	HellCannonWeaponType
-)
-
 type Weapon interface {
 	Id() WeaponId
-	Type() WeaponType
+	Type() armory.WeaponType
+	Ammunition() armory.Ammunition
+	SetAmmunition(id string) error
+	AmmunitionChanged() eventhandler.Event[Weapon, AmmunitionChangedEventArgs]
+	// Profile returns the ballistic profile of the loaded ammunition.
+	Profile() ballistics.Profile
+	// Sector returns the field of fire the weapon can traverse across.
+	Sector() ballistics.Sector
+	SetSector(heading float64, arc float64) error
+	SectorChanged() eventhandler.Event[Weapon, SectorChangedEventArgs]
+	// Solve returns the solution of firing on the position, which is out of arc if the position
+	// lies outside of the sector.
+	Solve(to math.Vector3) ballistics.Solution
+	// Aim returns the solution of firing with the elevation and azimuth in radians, which is out
+	// of arc if the azimuth lies outside of the sector.
+	Aim(elevation float64, azimuth float64) (ballistics.Solution, error)
 	Position() math.Vector3
 	SetPosition(v math.Vector3)
 	AddPosition(v math.Vector3)
@@ -31,18 +41,50 @@ type Weapon interface {
 	SetOwner(User)
 	OwnerChanged() eventhandler.Event[Weapon, OwnerChangedEventArgs]
 	IsOwned() bool
+	Acquire(user User, lease time.Duration) error
+	Release(user User) error
+	LeaseExpiresAt() (time.Time, bool)
+	HandoverRequester() User
+	RequestHandover(user User) error
+	RespondHandover(user User, accept bool) error
+	HandoverChanged() eventhandler.Event[Weapon, HandoverChangedEventArgs]
+	Solutions() []TargetSolution
+	PredictedImpact() *terrain.Prediction
+
+	setSolutions(solutions []TargetSolution)
+	setPredictedImpact(prediction *terrain.Prediction)
+	leave(user User)
+}
+
+type AmmunitionChangedEventArgs struct {
+	OldAmmunition armory.Ammunition
+	NewAmmunition armory.Ammunition
+}
+
+type SectorChangedEventArgs struct {
+	OldSector ballistics.Sector
+	NewSector ballistics.Sector
 }
 
 type weapon struct {
-	id       WeaponId
-	typ      WeaponType
-	position math.Vector3
-	active   bool
-	owner    User
+	id         WeaponId
+	typ        armory.WeaponType
+	ammunition armory.Ammunition
+	sector     ballistics.Sector
+	position   math.Vector3
+	active     bool
+
+	ownership ownership
 
-	positionEventHandler eventhandler.EventHandler[Weapon, PositionChangedEventArgs]
-	activeEventHandler   eventhandler.EventHandler[Weapon, ActiveChangedEventArgs]
-	ownerEventHandler    eventhandler.EventHandler[Weapon, OwnerChangedEventArgs]
+	solutions       []TargetSolution
+	predictedImpact *terrain.Prediction
+
+	positionEventHandler   eventhandler.EventHandler[Weapon, PositionChangedEventArgs]
+	activeEventHandler     eventhandler.EventHandler[Weapon, ActiveChangedEventArgs]
+	ownerEventHandler      eventhandler.EventHandler[Weapon, OwnerChangedEventArgs]
+	handoverEventHandler   eventhandler.EventHandler[Weapon, HandoverChangedEventArgs]
+	ammunitionEventHandler eventhandler.EventHandler[Weapon, AmmunitionChangedEventArgs]
+	sectorEventHandler     eventhandler.EventHandler[Weapon, SectorChangedEventArgs]
 
 	mtx sync.RWMutex
 }
@@ -51,10 +93,93 @@ func (w *weapon) Id() WeaponId {
 	return w.id
 }
 
-func (w *weapon) Type() WeaponType {
+func (w *weapon) Type() armory.WeaponType {
 	return w.typ
 }
 
+func (w *weapon) Ammunition() armory.Ammunition {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	return w.ammunition
+}
+
+// SetAmmunition loads the ammunition of the weapon type with the id.
+func (w *weapon) SetAmmunition(id string) error {
+	ammunition, err := w.typ.FindAmmunition(id)
+	if err != nil {
+		return err
+	}
+
+	w.mtx.Lock()
+
+	old := w.ammunition
+	w.ammunition = ammunition
+
+	w.mtx.Unlock()
+
+	w.ammunitionEventHandler.Invoke(w, AmmunitionChangedEventArgs{
+		OldAmmunition: old,
+		NewAmmunition: ammunition,
+	})
+
+	return nil
+}
+
+func (w *weapon) AmmunitionChanged() eventhandler.Event[Weapon, AmmunitionChangedEventArgs] {
+	return w.ammunitionEventHandler
+}
+
+func (w *weapon) Profile() ballistics.Profile {
+	return w.typ.AmmunitionProfile(w.Ammunition())
+}
+
+func (w *weapon) Sector() ballistics.Sector {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	return w.sector
+}
+
+// SetSector sets the field of fire to the arc in radians centered on the heading in radians.
+func (w *weapon) SetSector(heading float64, arc float64) error {
+	sector, err := w.typ.Sector(heading, arc)
+	if err != nil {
+		return err
+	}
+
+	w.mtx.Lock()
+
+	old := w.sector
+	w.sector = sector
+
+	w.mtx.Unlock()
+
+	w.sectorEventHandler.Invoke(w, SectorChangedEventArgs{
+		OldSector: old,
+		NewSector: sector,
+	})
+
+	return nil
+}
+
+func (w *weapon) SectorChanged() eventhandler.Event[Weapon, SectorChangedEventArgs] {
+	return w.sectorEventHandler
+}
+
+func (w *weapon) Solve(to math.Vector3) ballistics.Solution {
+	return w.Sector().Restrict(w.Profile().Solve(w.Position(), to))
+}
+
+func (w *weapon) Aim(elevation float64, azimuth float64) (ballistics.Solution, error) {
+	solution, err := w.Profile().Aim(elevation, azimuth)
+	if err != nil {
+		return ballistics.Solution{}, err
+	}
+
+	return w.Sector().Restrict(solution), nil
+}
+
 func (w *weapon) Position() math.Vector3 {
 	w.mtx.RLock()
 	defer w.mtx.RUnlock()
@@ -112,31 +237,157 @@ func (w *weapon) SetActive(v bool) {
 }
 
 func (w *weapon) Owner() User {
-	w.mtx.RLock()
-	defer w.mtx.RUnlock()
-
-	return w.owner
+	return w.ownership.Owner()
 }
 
+// SetOwner replaces the owner regardless of the current owner or lease.
 func (w *weapon) SetOwner(u User) {
-	w.mtx.Lock()
+	old := w.ownership.force(u)
+
+	w.ownerEventHandler.Invoke(w, OwnerChangedEventArgs{
+		OldUser: old,
+		NewUser: u,
+	})
+}
 
-	old := w.owner
-	w.owner = u
+func (w *weapon) IsOwned() bool {
+	return w.ownership.Owner() != nil
+}
 
-	w.mtx.Unlock()
+// Acquire makes the user the owner for the lease duration or renews the lease if the user
+// already owns it. It fails with ErrAlreadyOwned if another user owns it. A zero lease never expires.
+func (w *weapon) Acquire(u User, lease time.Duration) error {
+	old, err := w.ownership.acquire(u, lease, w.expireLease)
+	if err != nil {
+		return err
+	}
 
 	w.ownerEventHandler.Invoke(w, OwnerChangedEventArgs{
 		OldUser: old,
 		NewUser: u,
 	})
+
+	return nil
 }
 
-func (w *weapon) IsOwned() bool {
+func (w *weapon) Release(u User) error {
+	old, err := w.ownership.release(u)
+	if err != nil {
+		return err
+	}
+
+	w.ownerEventHandler.Invoke(w, OwnerChangedEventArgs{
+		OldUser: old,
+		NewUser: nil,
+	})
+
+	return nil
+}
+
+func (w *weapon) expireLease(generation uint64) {
+	old, ok := w.ownership.expire(generation)
+	if !ok {
+		return
+	}
+
+	w.ownerEventHandler.Invoke(w, OwnerChangedEventArgs{
+		OldUser: old,
+		NewUser: nil,
+	})
+}
+
+// leave releases the weapon if the user owns it and dismisses a handover requested by the user.
+func (w *weapon) leave(u User) {
+	old, dismissed := w.ownership.leave(u)
+
+	if old != nil {
+		w.ownerEventHandler.Invoke(w, OwnerChangedEventArgs{
+			OldUser: old,
+			NewUser: nil,
+		})
+	}
+
+	if dismissed {
+		w.handoverEventHandler.Invoke(w, HandoverChangedEventArgs{
+			OldRequester: u,
+			NewRequester: nil,
+		})
+	}
+}
+
+func (w *weapon) LeaseExpiresAt() (time.Time, bool) {
+	return w.ownership.LeaseExpiresAt()
+}
+
+func (w *weapon) HandoverRequester() User {
+	return w.ownership.HandoverRequester()
+}
+
+func (w *weapon) RequestHandover(u User) error {
+	old, err := w.ownership.requestHandover(u)
+	if err != nil {
+		return err
+	}
+
+	w.handoverEventHandler.Invoke(w, HandoverChangedEventArgs{
+		OldRequester: old,
+		NewRequester: u,
+	})
+
+	return nil
+}
+
+// RespondHandover hands the ownership over to the requesting user if accepted, otherwise the request is dismissed.
+func (w *weapon) RespondHandover(u User, accept bool) error {
+	old, requester, err := w.ownership.respondHandover(u, accept, w.expireLease)
+	if err != nil {
+		return err
+	}
+
+	if accept {
+		w.ownerEventHandler.Invoke(w, OwnerChangedEventArgs{
+			OldUser: old,
+			NewUser: requester,
+		})
+
+		return nil
+	}
+
+	w.handoverEventHandler.Invoke(w, HandoverChangedEventArgs{
+		OldRequester: requester,
+		NewRequester: nil,
+	})
+
+	return nil
+}
+
+func (w *weapon) Solutions() []TargetSolution {
 	w.mtx.RLock()
 	defer w.mtx.RUnlock()
 
-	return w.owner != nil
+	return w.solutions
+}
+
+func (w *weapon) setSolutions(solutions []TargetSolution) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.solutions = solutions
+}
+
+// PredictedImpact returns the predicted impact marker placed by a gunner or nil if there is none.
+func (w *weapon) PredictedImpact() *terrain.Prediction {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	return w.predictedImpact
+}
+
+func (w *weapon) setPredictedImpact(prediction *terrain.Prediction) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.predictedImpact = prediction
 }
 
 func (w *weapon) PositionChanged() eventhandler.Event[Weapon, PositionChangedEventArgs] {
@@ -151,16 +402,27 @@ func (w *weapon) OwnerChanged() eventhandler.Event[Weapon, OwnerChangedEventArgs
 	return w.ownerEventHandler
 }
 
-func newWeapon(id WeaponId, typ WeaponType) Weapon {
+func (w *weapon) HandoverChanged() eventhandler.Event[Weapon, HandoverChangedEventArgs] {
+	return w.handoverEventHandler
+}
+
+func newWeapon(id WeaponId, typ armory.WeaponType) Weapon {
 	return &weapon{
 		id,
 		typ,
+		typ.DefaultAmmunition(),
+		typ.DefaultSector(),
 		math.Vector3{},
 		false,
+		ownership{},
+		make([]TargetSolution, 0),
 		nil,
 		eventhandler.New[Weapon, PositionChangedEventArgs](),
 		eventhandler.New[Weapon, ActiveChangedEventArgs](),
 		eventhandler.New[Weapon, OwnerChangedEventArgs](),
+		eventhandler.New[Weapon, HandoverChangedEventArgs](),
+		eventhandler.New[Weapon, AmmunitionChangedEventArgs](),
+		eventhandler.New[Weapon, SectorChangedEventArgs](),
 		sync.RWMutex{},
 	}
 }
@@ -0,0 +1,52 @@
+package terrain
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+)
+
+const (
+	// clearanceStep is the horizontal distance in meters between two samples of a trajectory.
+	clearanceStep = 5
+	// clearanceMargin is the horizontal distance in meters around the weapon and the target which
+	// is not sampled, as both are usually placed right on the terrain.
+	clearanceMargin = 10
+)
+
+// Clearance is the result of checking a trajectory against the terrain. Obstruction is the first
+// sampled point of the trajectory below the terrain and only set if the trajectory is not clear.
+type Clearance struct {
+	Clear       bool
+	Obstruction math.Vector3
+}
+
+// CheckClearance samples the trajectory of the solution fired from from and reports whether it
+// passes above the terrain. It returns false if there is no heightmap or the solution is not in range.
+func CheckClearance(heightmap Heightmap, from math.Vector3, solution ballistics.Solution) (Clearance, bool) {
+	if heightmap == nil || !solution.InRange() {
+		return Clearance{}, false
+	}
+
+	end := solution.HorizontalDistance - clearanceMargin
+	for distance := float64(clearanceMargin); distance < end; distance += clearanceStep {
+		position := solution.PositionAt(from, distance)
+
+		height, ok := heightmap.Height(position)
+		if !ok || position.Z >= height {
+			continue
+		}
+
+		return Clearance{
+			Clear: false,
+			Obstruction: math.Vector3{
+				X: position.X,
+				Y: position.Y,
+				Z: height,
+			},
+		}, true
+	}
+
+	return Clearance{
+		Clear: true,
+	}, true
+}
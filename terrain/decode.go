@@ -0,0 +1,61 @@
+package terrain
+
+import (
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"image/png"
+	"io"
+	stdmath "math"
+)
+
+// decodePNG decodes a grayscale PNG into raw 16 bit height values. 8 bit images are scaled up to 16 bit.
+func decodePNG(r io.Reader) (int, int, []uint16, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	bounds := img.Bounds()
+	columns := bounds.Dx()
+	rows := bounds.Dy()
+
+	values := make([]uint16, 0, columns*rows)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			values = append(values, color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y)
+		}
+	}
+
+	return columns, rows, values, nil
+}
+
+// decodeRaw decodes a grid of little endian 16 bit height values. If columns is zero, the grid is
+// expected to be square.
+func decodeRaw(r io.Reader, columns int) (int, int, []uint16, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	if len(data)%2 != 0 {
+		return 0, 0, nil, errors.New("raw heightmap has an odd number of bytes")
+	}
+
+	count := len(data) / 2
+
+	if columns == 0 {
+		columns = int(stdmath.Sqrt(float64(count)))
+	}
+
+	if columns == 0 || count%columns != 0 {
+		return 0, 0, nil, errors.New("raw heightmap size does not match its columns")
+	}
+
+	values := make([]uint16, count)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+
+	return columns, count / columns, values, nil
+}
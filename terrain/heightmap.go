@@ -0,0 +1,79 @@
+package terrain
+
+import (
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+	stdmath "math"
+)
+
+type Heightmap interface {
+	// Height returns the interpolated terrain height in meters at the position. The height of
+	// the position itself is ignored. It returns false if the position lies outside of the map.
+	Height(position math.Vector3) (float32, bool)
+}
+
+// heightmap is a grid of height samples spanning the bounds of a map. The first sample is
+// located at the origin of the map, the last one at the opposite corner.
+type heightmap struct {
+	gameMap gamemap.Map
+	columns int
+	rows    int
+	samples []float32
+}
+
+func (h *heightmap) Height(position math.Vector3) (float32, bool) {
+	if !h.gameMap.Contains(position) {
+		return 0, false
+	}
+
+	x := float64(position.X-h.gameMap.Origin.X) / float64(h.gameMap.Width) * float64(h.columns-1)
+	y := float64(position.Y-h.gameMap.Origin.Y) / float64(h.gameMap.Height) * float64(h.rows-1)
+
+	x0 := int(stdmath.Floor(x))
+	y0 := int(stdmath.Floor(y))
+	x1 := min(x0+1, h.columns-1)
+	y1 := min(y0+1, h.rows-1)
+
+	fx := float32(x - float64(x0))
+	fy := float32(y - float64(y0))
+
+	top := lerp(h.sample(x0, y0), h.sample(x1, y0), fx)
+	bottom := lerp(h.sample(x0, y1), h.sample(x1, y1), fx)
+
+	return lerp(top, bottom, fy), true
+}
+
+func (h *heightmap) sample(x int, y int) float32 {
+	return h.samples[y*h.columns+x]
+}
+
+// NewHeightmap creates a heightmap of the map from samples in meters ordered row by row from north to south.
+func NewHeightmap(gameMap gamemap.Map, columns int, rows int, samples []float32) (Heightmap, error) {
+	if columns < 2 || rows < 2 {
+		return nil, errors.New("heightmap needs at least two columns and rows")
+	}
+
+	if len(samples) != columns*rows {
+		return nil, errors.New("heightmap sample count does not match its size")
+	}
+
+	return &heightmap{
+		gameMap,
+		columns,
+		rows,
+		samples,
+	}, nil
+}
+
+func lerp(a float32, b float32, t float32) float32 {
+	return a + (b-a)*t
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
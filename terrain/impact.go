@@ -0,0 +1,75 @@
+package terrain
+
+import (
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/ballistics"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/math"
+)
+
+// impactRefinements is the number of bisection steps used to refine a sampled terrain impact.
+const impactRefinements = 10
+
+// Prediction is the predicted impact of firing a solution. If the trajectory could not be
+// intersected with the terrain, the impact is the point at the height the projectile was fired from.
+type Prediction struct {
+	Solution           ballistics.Solution
+	Impact             ballistics.Impact
+	TerrainIntersected bool
+}
+
+// PredictImpact predicts where the solution fired from from lands. The heightmap may be nil.
+func PredictImpact(heightmap Heightmap, from math.Vector3, solution ballistics.Solution) Prediction {
+	if impact, ok := intersect(heightmap, from, solution); ok {
+		return Prediction{
+			Solution:           solution,
+			Impact:             impact,
+			TerrainIntersected: true,
+		}
+	}
+
+	return Prediction{
+		Solution: solution,
+		Impact:   solution.ImpactAt(from, solution.HorizontalDistance),
+	}
+}
+
+// intersect samples the trajectory until it drops below the terrain and refines the impact by
+// bisection. It returns false if the trajectory leaves the heightmap before.
+func intersect(heightmap Heightmap, from math.Vector3, solution ballistics.Solution) (ballistics.Impact, bool) {
+	if heightmap == nil {
+		return ballistics.Impact{}, false
+	}
+
+	above := float64(clearanceMargin)
+	for distance := above; ; distance += clearanceStep {
+		position := solution.PositionAt(from, distance)
+
+		height, ok := heightmap.Height(position)
+		if !ok {
+			return ballistics.Impact{}, false
+		}
+
+		if position.Z >= height {
+			above = distance
+			continue
+		}
+
+		below := distance
+		for i := 0; i < impactRefinements; i++ {
+			middle := (above + below) / 2
+			position := solution.PositionAt(from, middle)
+
+			if height, ok := heightmap.Height(position); ok && position.Z < height {
+				below = middle
+			} else {
+				above = middle
+			}
+		}
+
+		impact := solution.ImpactAt(from, below)
+		if height, ok := heightmap.Height(impact.Position); ok {
+			impact.Position.Z = height
+		}
+
+		return impact, true
+	}
+}
@@ -0,0 +1,138 @@
+package terrain
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pixlcrashr/squadmortar.xyz-sessions-server/gamemap"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrHeightmapNotFound = errors.New("heightmap not found")
+
+type Store interface {
+	Heightmap(mapId string) (Heightmap, error)
+}
+
+type store struct {
+	heightmaps map[string]Heightmap
+}
+
+func (s *store) Heightmap(mapId string) (Heightmap, error) {
+	heightmap, ok := s.heightmaps[mapId]
+	if !ok {
+		return nil, ErrHeightmapNotFound
+	}
+
+	return heightmap, nil
+}
+
+func NewStore(heightmaps map[string]Heightmap) Store {
+	return &store{
+		heightmaps,
+	}
+}
+
+// Meta describes how raw heightmap values are converted into meters: height = offset + value * scale.
+// Columns is only used for raw grids, which are expected to be square if it is zero.
+type Meta struct {
+	Scale   float32 `json:"scale"`
+	Offset  float32 `json:"offset"`
+	Columns int     `json:"columns"`
+}
+
+// DefaultMeta interprets raw values as centimeters above zero.
+var DefaultMeta = Meta{
+	Scale: 0.01,
+}
+
+// LoadStore loads the heightmaps of all maps of the catalog from the directory. A heightmap is
+// either stored as <map id>.png (16 bit grayscale) or <map id>.raw (little endian 16 bit grid)
+// with an optional <map id>.json containing its Meta. Maps without a heightmap are skipped. An
+// empty directory results in an empty store.
+func LoadStore(dir string, catalog gamemap.Catalog, logger *zap.Logger) (Store, error) {
+	heightmaps := make(map[string]Heightmap)
+
+	if dir == "" {
+		return NewStore(heightmaps), nil
+	}
+
+	for _, m := range catalog.Maps() {
+		heightmap, err := loadHeightmap(dir, m)
+		if err != nil {
+			if errors.Is(err, ErrHeightmapNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		heightmaps[m.Id] = heightmap
+		logger.Info("loaded heightmap", zap.String("map", m.Id))
+	}
+
+	return NewStore(heightmaps), nil
+}
+
+func loadHeightmap(dir string, m gamemap.Map) (Heightmap, error) {
+	meta, err := loadMeta(filepath.Join(dir, m.Id+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	decoders := []struct {
+		ext    string
+		decode func(r io.Reader) (int, int, []uint16, error)
+	}{
+		{".png", decodePNG},
+		{".raw", func(r io.Reader) (int, int, []uint16, error) {
+			return decodeRaw(r, meta.Columns)
+		}},
+	}
+
+	for _, decoder := range decoders {
+		f, err := os.Open(filepath.Join(dir, m.Id+decoder.ext))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		columns, rows, values, err := decoder.decode(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		samples := make([]float32, len(values))
+		for i, v := range values {
+			samples[i] = meta.Offset + float32(v)*meta.Scale
+		}
+
+		return NewHeightmap(m, columns, rows, samples)
+	}
+
+	return nil, ErrHeightmapNotFound
+}
+
+func loadMeta(path string) (Meta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return DefaultMeta, nil
+		}
+
+		return Meta{}, err
+	}
+
+	meta := DefaultMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Meta{}, err
+	}
+
+	return meta, nil
+}